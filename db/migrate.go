@@ -1,47 +1,88 @@
 package main
 
 import (
+	"embed"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
+// migrationsFS embeds the schema into the binary itself, so a container
+// only needs the compiled migrate tool - not a migrations/ directory
+// shipped alongside it - to run against a fresh database.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationsDir is where "create" writes new migration files; it's a
+// development-time operation against the real filesystem; everything else
+// reads from the embedded migrationsFS instead.
+const migrationsDir = "migrations"
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run migrate.go <command>")
-		fmt.Println("Commands: up, down, version")
-		fmt.Println("Set DBSTRING environment variable for database connection")
+	dryRun := flag.Bool("dry-run", false, "log the migration that would run without applying it")
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 1 {
+		usage()
 		os.Exit(1)
 	}
+	cmd := args[0]
+
+	// create only touches the filesystem, so it runs before DBSTRING is
+	// required at all.
+	if cmd == "create" {
+		if len(args) < 2 {
+			log.Fatal("Usage: create <name>")
+		}
+		if err := createMigration(args[1]); err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		return
+	}
 
 	dbURL := os.Getenv("DBSTRING")
 	if dbURL == "" {
 		log.Fatal("DBSTRING environment variable is required")
 	}
 
-	m, err := migrate.New("file://migrations", dbURL)
+	src, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		log.Fatalf("Failed to load embedded migrations: %v", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, dbURL)
 	if err != nil {
 		log.Fatalf("Failed to create migrate instance: %v", err)
 	}
 	defer m.Close()
 
-	cmd := os.Args[1]
-
 	switch cmd {
 	case "up":
-		err = m.Up()
-		if err != nil && err != migrate.ErrNoChange {
+		if *dryRun {
+			logPlannedVersion(m, "up")
+			return
+		}
+		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 			log.Fatalf("Migration up failed: %v", err)
 		}
 		fmt.Println("Migration up completed")
 
 	case "down":
-		err = m.Down()
-		if err != nil && err != migrate.ErrNoChange {
+		if *dryRun {
+			logPlannedVersion(m, "down")
+			return
+		}
+		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
 			log.Fatalf("Migration down failed: %v", err)
 		}
 		fmt.Println("Migration down completed")
@@ -53,8 +94,115 @@ func main() {
 		}
 		fmt.Printf("Version: %d, Dirty: %v\n", version, dirty)
 
+	case "goto":
+		if len(args) < 2 {
+			log.Fatal("Usage: goto <version>")
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid version %q: %v", args[1], err)
+		}
+		if *dryRun {
+			fmt.Printf("[dry-run] would migrate to version %d\n", version)
+			return
+		}
+		if err := m.Migrate(uint(version)); err != nil && err != migrate.ErrNoChange {
+			log.Fatalf("Migration to version %d failed: %v", version, err)
+		}
+		fmt.Printf("Migrated to version %d\n", version)
+
+	case "force":
+		if len(args) < 2 {
+			log.Fatal("Usage: force <version>")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatalf("Invalid version %q: %v", args[1], err)
+		}
+		if *dryRun {
+			fmt.Printf("[dry-run] would force version to %d\n", version)
+			return
+		}
+		if err := m.Force(version); err != nil {
+			log.Fatalf("Force to version %d failed: %v", version, err)
+		}
+		fmt.Printf("Forced version to %d\n", version)
+
+	case "steps":
+		if len(args) < 2 {
+			log.Fatal("Usage: steps <n>")
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatalf("Invalid step count %q: %v", args[1], err)
+		}
+		if *dryRun {
+			fmt.Printf("[dry-run] would step migrations by %d\n", n)
+			return
+		}
+		if err := m.Steps(n); err != nil && err != migrate.ErrNoChange {
+			log.Fatalf("Steps(%d) failed: %v", n, err)
+		}
+		fmt.Printf("Stepped migrations by %d\n", n)
+
 	default:
-		fmt.Println("Unknown command. Use: up, down, version")
+		usage()
 		os.Exit(1)
 	}
 }
+
+// logPlannedVersion prints the version up/down would start from under
+// --dry-run, since golang-migrate doesn't expose the target version of an
+// Up/Down run without actually applying it.
+func logPlannedVersion(m *migrate.Migrate, direction string) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Printf("[dry-run] would migrate %s from version <none> (no migrations applied yet)\n", direction)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to read current version: %v", err)
+	}
+	fmt.Printf("[dry-run] would migrate %s from version %d (dirty=%v)\n", direction, version, dirty)
+}
+
+// createMigration writes an empty NNNN_name.up.sql/.down.sql pair to
+// migrationsDir, numbered one past the highest sequence number already
+// present, following this repo's existing 0001_, 0002_, ... convention.
+func createMigration(name string) error {
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", migrationsDir, err)
+	}
+
+	next := 1
+	for _, e := range entries {
+		var seq int
+		if _, err := fmt.Sscanf(e.Name(), "%d_", &seq); err == nil && seq >= next {
+			next = seq + 1
+		}
+	}
+
+	base := fmt.Sprintf("%04d_%s", next, name)
+	for _, suffix := range []string{"up", "down"} {
+		path := filepath.Join(migrationsDir, fmt.Sprintf("%s.%s.sql", base, suffix))
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Printf("Created %s\n", path)
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Println("Usage: go run migrate.go [--dry-run] <command> [args]")
+	fmt.Println("Commands:")
+	fmt.Println("  up                apply all pending migrations")
+	fmt.Println("  down              roll back all migrations")
+	fmt.Println("  version           print the current version")
+	fmt.Println("  goto <version>    migrate to a specific version")
+	fmt.Println("  force <version>   set the version without running migrations, clearing dirty")
+	fmt.Println("  steps <n>         apply n migrations (negative to roll back)")
+	fmt.Println("  create <name>     write a new NNNN_name.up/.down.sql pair to migrations/")
+	fmt.Println("Set DBSTRING environment variable for database connection")
+}
@@ -4,14 +4,16 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafidoth/train-ticket-booking-microservice/auth/tokens"
 )
 
 type AuthHandler struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	signer tokens.TokenSigner
 }
 
-func New(conn *pgxpool.Pool) *AuthHandler {
-	return &AuthHandler{db: conn}
+func New(conn *pgxpool.Pool, signer tokens.TokenSigner) *AuthHandler {
+	return &AuthHandler{db: conn, signer: signer}
 }
 
 type User struct {
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rafidoth/train-ticket-booking-microservice/auth/tokens"
+)
+
+// jwksPublisher is implemented by TokenSigners that have real public keys
+// to publish (RS256). HMAC signing is symmetric and has nothing to expose
+// here.
+type jwksPublisher interface {
+	PublicJWKS() []tokens.JWK
+}
+
+type jwksResponse struct {
+	Keys []tokens.JWK `json:"keys"`
+}
+
+// JWKS serves the signing keys other services can use to verify tokens
+// independently of a shared secret. Returns an empty key set when the
+// active signer is HMAC-based (a symmetric secret, which by definition
+// can't be published here) - still a stable endpoint for callers to poll,
+// and one that starts returning real keys the moment the auth service is
+// switched to RS256.
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	keys := []tokens.JWK{}
+	if publisher, ok := h.signer.(jwksPublisher); ok {
+		keys = publisher.PublicJWKS()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(jwksResponse{Keys: keys})
+}
@@ -5,7 +5,7 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/rafidoth/train-ticket-booking-microservice/auth/tokens"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -111,7 +111,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	_, tokenSpan := tracer.Start(ctx, "Register.GenerateToken")
 	tokenSpan.SetAttributes(attribute.String("user.id", user.ID))
 
-	token, err := generateToken(user.ID, user.Email)
+	token, err := h.generateToken(user.ID, user.Email)
 	if err != nil {
 		tokenSpan.RecordError(err)
 		tokenSpan.SetStatus(codes.Error, "token generation failed")
@@ -134,16 +134,15 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-var jwtSecret = []byte("team-deadlock")
-
-func generateToken(userID, email string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"email":   email,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+// generateToken delegates to h.signer so the signing scheme (HS256 today,
+// RS256 when JWT_SIGNING_METHOD=RS256) stays a deployment concern rather
+// than something baked into the handler.
+func (h *AuthHandler) generateToken(userID, email string) (string, error) {
+	return h.signer.Sign(tokens.Claims{
+		UserID:    userID,
+		Email:     email,
+		Roles:     []string{"user"},
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	})
 }
@@ -9,6 +9,8 @@ import (
 	"github.com/rafidoth/train-ticket-booking-microservice/auth/config"
 	"github.com/rafidoth/train-ticket-booking-microservice/auth/db"
 	"github.com/rafidoth/train-ticket-booking-microservice/auth/handlers"
+	"github.com/rafidoth/train-ticket-booking-microservice/auth/health"
+	"github.com/rafidoth/train-ticket-booking-microservice/auth/tokens"
 	"github.com/rafidoth/train-ticket-booking-microservice/auth/tracing"
 )
 
@@ -56,7 +58,16 @@ func main() {
 		slog.Error("unable to configure db : ", "error", err)
 	}
 
-	handler := handlers.New(conn)
-	server := NewServer(handler, cfg)
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register(health.DBChecker(conn), true, 0)
+
+	signer, err := tokens.NewFromEnv()
+	if err != nil {
+		slog.Error("failed to configure token signer", "error", err)
+		os.Exit(1)
+	}
+
+	handler := handlers.New(conn, signer)
+	server := NewServer(handler, healthRegistry, cfg)
 	server.Start()
 }
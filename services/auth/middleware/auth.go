@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type contextKey string
+
+const (
+	ctxKeyUserID contextKey = "user_id"
+	ctxKeyEmail  contextKey = "email"
+	ctxKeyRoles  contextKey = "roles"
+)
+
+// jwtSecret is the HS256 verification path, used when a token arrives
+// without a matching RS256 key in defaultJWKSCache. It falls back to a
+// built-in literal when JWT_SECRET is unset; the auth service has no such
+// fallback and refuses to start without JWT_SECRET/JWT_SECRET_FILE, so
+// tokens only verify here when JWT_SECRET matches the auth service's
+// secret, or when the auth service uses JWT_SIGNING_METHOD=RS256 and
+// tokens are verified via JWKS instead.
+func jwtSecret() []byte {
+	if s := os.Getenv("JWT_SECRET"); s != "" {
+		return []byte(s)
+	}
+	return []byte("team-deadlock")
+}
+
+// RequireAuth verifies the Bearer token on every request, rejecting missing
+// or invalid tokens with 401, and stashes the claims in the request context
+// for downstream handlers (and RequireRole) to read.
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := bearerToken(r)
+		if tokenString == "" {
+			http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			switch t.Method.(type) {
+			case *jwt.SigningMethodRSA:
+				if key, ok := defaultJWKSCache.key(kid); ok {
+					return key, nil
+				}
+				return nil, jwt.ErrTokenUnverifiable
+			case *jwt.SigningMethodHMAC:
+				return jwtSecret(), nil
+			default:
+				return nil, jwt.ErrTokenSignatureInvalid
+			}
+		})
+		if err != nil {
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := r.Context()
+		if userID, ok := claims["user_id"].(string); ok {
+			ctx = context.WithValue(ctx, ctxKeyUserID, userID)
+		}
+		if email, ok := claims["email"].(string); ok {
+			ctx = context.WithValue(ctx, ctxKeyEmail, email)
+		}
+		ctx = context.WithValue(ctx, ctxKeyRoles, rolesFromClaims(claims))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireRole wraps a handler so it's only reachable by a caller whose token
+// carries the given role; it must run after RequireAuth.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, have := range Roles(r.Context()) {
+				if have == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
+
+// UserID returns the authenticated caller's user id, if RequireAuth ran.
+func UserID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKeyUserID).(string)
+	return id, ok
+}
+
+// Roles returns the authenticated caller's roles, if RequireAuth ran.
+func Roles(ctx context.Context) []string {
+	roles, _ := ctx.Value(ctxKeyRoles).([]string)
+	return roles
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
+
+// rolesFromClaims defaults to ["user"] so tokens issued before the roles
+// claim existed still pass a RequireRole("user") check.
+func rolesFromClaims(claims jwt.MapClaims) []string {
+	raw, ok := claims["roles"].([]interface{})
+	if !ok {
+		return []string{"user"}
+	}
+	roles := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := r.(string); ok {
+			roles = append(roles, s)
+		}
+	}
+	if len(roles) == 0 {
+		return []string{"user"}
+	}
+	return roles
+}
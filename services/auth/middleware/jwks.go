@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksCache fetches and caches the auth service's JSON Web Key Set so
+// RequireAuth can verify RS256 tokens against the public key matching a
+// token's kid, without sharing a secret. It refreshes on a TTL and eagerly
+// on an unrecognized kid, so a newly rotated-in key is picked up without
+// waiting out the full TTL.
+type jwksCache struct {
+	url string
+	ttl time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+var defaultJWKSCache = newJWKSCache()
+
+// newJWKSCache reads the JWKS URL from AUTH_JWKS_URL, or derives it from
+// AUTH_SERVICE_URL when set. Leaving both unset disables RS256 verification
+// entirely (key() always misses), which is fine for the default HS256 setup.
+func newJWKSCache() *jwksCache {
+	url := os.Getenv("AUTH_JWKS_URL")
+	if url == "" {
+		if base := os.Getenv("AUTH_SERVICE_URL"); base != "" {
+			url = strings.TrimRight(base, "/") + "/.well-known/jwks.json"
+		}
+	}
+	return &jwksCache{url: url, ttl: 5 * time.Minute}
+}
+
+func (c *jwksCache) key(kid string) (*rsa.PublicKey, bool) {
+	if c.url == "" {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	key, ok := c.keys[kid]
+	stale := time.Since(c.fetchedAt) > c.ttl
+	c.mu.Unlock()
+
+	if ok && !stale {
+		return key, true
+	}
+
+	// Refresh on a stale cache or an unrecognized kid - the latter covers a
+	// key that rotated in after our last fetch. Serve the stale entry, if
+	// any, when the refresh itself fails.
+	if err := c.refresh(); err != nil {
+		return key, ok
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok = c.keys[kid]
+	return key, ok
+}
+
+func (c *jwksCache) refresh() error {
+	resp, err := http.Get(c.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(body.Keys))
+	for _, k := range body.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := rsaPublicKeyFromJWK(k.N, k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+func rsaPublicKeyFromJWK(nEnc, eEnc string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(nEnc)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(eEnc)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
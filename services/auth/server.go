@@ -9,6 +9,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rafidoth/train-ticket-booking-microservice/auth/config"
 	"github.com/rafidoth/train-ticket-booking-microservice/auth/handlers"
+	"github.com/rafidoth/train-ticket-booking-microservice/auth/health"
 	"github.com/rafidoth/train-ticket-booking-microservice/auth/middleware"
 	"github.com/riandyrn/otelchi"
 )
@@ -16,14 +17,16 @@ import (
 type Server struct {
 	router  *chi.Mux
 	handler *handlers.AuthHandler
+	health  *health.Registry
 	cfg     *config.Config
 }
 
-func NewServer(authHandler *handlers.AuthHandler, cfg *config.Config) *Server {
+func NewServer(authHandler *handlers.AuthHandler, healthRegistry *health.Registry, cfg *config.Config) *Server {
 	return &Server{
 		router:  chi.NewRouter(),
 		cfg:     cfg,
 		handler: authHandler,
+		health:  healthRegistry,
 	}
 }
 
@@ -37,11 +40,16 @@ func (s *Server) registerRoutes() {
 	// Expose metrics endpoint for Prometheus scraping
 	s.router.Handle("/metrics", promhttp.Handler())
 
-	// Health check endpoint
-	s.router.Get("/health", s.handler.Health)
+	// Health check endpoints: /health/live for liveness, /health/ready for
+	// readiness (503s while a critical dependency is failing), /health for
+	// the full aggregate report.
+	s.router.Get("/health/live", s.health.Live)
+	s.router.Get("/health/ready", s.health.Ready)
+	s.router.Get("/health", s.health.Health)
 
 	s.router.Post("/register", s.handler.Register)
 	s.router.Post("/login", s.handler.Login)
+	s.router.Get("/.well-known/jwks.json", s.handler.JWKS)
 }
 
 func (s *Server) Start() {
@@ -0,0 +1,96 @@
+package tokens
+
+import (
+	"errors"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrMissingSecret is returned when JWT_SECRET/JWT_SECRET_FILE aren't set.
+// There's no safe fallback for a missing signing secret - a literal baked
+// into source would let anyone forge tokens in any deployment that forgets
+// to configure it - so we fail closed instead.
+var ErrMissingSecret = errors.New("tokens: JWT_SECRET or JWT_SECRET_FILE is required")
+
+// HMACSigner signs and verifies tokens with a symmetric secret. It trusts
+// one or more secrets keyed by kid so a secret retired from signing can
+// still verify tokens issued before the rotation, while new tokens are
+// always signed under activeKeyID.
+type HMACSigner struct {
+	activeKeyID string
+	secrets     map[string][]byte
+}
+
+// NewHMACSigner builds an HMACSigner that signs with active under
+// activeKeyID, additionally trusting the secrets in previous for
+// verification only (pass nil when there's no retired secret yet).
+func NewHMACSigner(activeKeyID string, active []byte, previous map[string][]byte) *HMACSigner {
+	secrets := make(map[string][]byte, len(previous)+1)
+	for kid, secret := range previous {
+		secrets[kid] = secret
+	}
+	secrets[activeKeyID] = active
+	return &HMACSigner{activeKeyID: activeKeyID, secrets: secrets}
+}
+
+// NewHMACSignerFromEnv loads the active secret from JWT_SECRET (or
+// JWT_SECRET_FILE, for a secret mounted from a file rather than an env
+// var). There's no fallback: a deployment that forgets to set either one
+// fails closed with ErrMissingSecret rather than signing and verifying
+// every token under a publicly-known literal. A secret being rotated out
+// can stay verification-only by setting JWT_PREVIOUS_SECRET_KID alongside
+// JWT_PREVIOUS_SECRET or JWT_PREVIOUS_SECRET_FILE.
+func NewHMACSignerFromEnv() (*HMACSigner, error) {
+	active, err := secretFromEnv("JWT_SECRET", "JWT_SECRET_FILE")
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 {
+		return nil, ErrMissingSecret
+	}
+	activeKeyID := os.Getenv("JWT_SECRET_KID")
+	if activeKeyID == "" {
+		activeKeyID = "hmac-default"
+	}
+
+	previous := map[string][]byte{}
+	if prevKeyID := os.Getenv("JWT_PREVIOUS_SECRET_KID"); prevKeyID != "" {
+		prevSecret, err := secretFromEnv("JWT_PREVIOUS_SECRET", "JWT_PREVIOUS_SECRET_FILE")
+		if err != nil {
+			return nil, err
+		}
+		if len(prevSecret) > 0 {
+			previous[prevKeyID] = prevSecret
+		}
+	}
+
+	return NewHMACSigner(activeKeyID, active, previous), nil
+}
+
+func (s *HMACSigner) KeyID() string { return s.activeKeyID }
+
+func (s *HMACSigner) Sign(claims Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claimsToMapClaims(claims))
+	token.Header["kid"] = s.activeKeyID
+	return token.SignedString(s.secrets[s.activeKeyID])
+}
+
+func (s *HMACSigner) Verify(tokenString string) (Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		kid, _ := t.Header["kid"].(string)
+		secret, ok := s.secrets[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+	return claimsFromMapClaims(claims), nil
+}
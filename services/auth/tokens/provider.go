@@ -0,0 +1,24 @@
+package tokens
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NewFromEnv builds the TokenSigner the auth service signs with, selected
+// by JWT_SIGNING_METHOD ("HS256", the default, or "RS256"). RS256 is the
+// scheme that lets /.well-known/jwks.json publish real verification keys;
+// HS256 is the shared-secret path and still needs JWT_SECRET or
+// JWT_SECRET_FILE set (NewHMACSignerFromEnv returns ErrMissingSecret
+// otherwise).
+func NewFromEnv() (TokenSigner, error) {
+	switch method := strings.ToUpper(os.Getenv("JWT_SIGNING_METHOD")); method {
+	case "", "HS256":
+		return NewHMACSignerFromEnv()
+	case "RS256":
+		return NewRSASignerFromEnv()
+	default:
+		return nil, fmt.Errorf("tokens: unsupported JWT_SIGNING_METHOD %q", method)
+	}
+}
@@ -0,0 +1,168 @@
+package tokens
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWK is a single entry of a JSON Web Key Set (RFC 7517), describing one
+// RSA public key other services can fetch to verify tokens without sharing
+// a secret with the auth service.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// RSASigner signs tokens with an RSA private key (RS256) and verifies them
+// against one or more public keys keyed by kid, so a new key can be rolled
+// out and the old one retired from signing - while it's still published for
+// verification - without any in-flight token becoming unverifiable.
+type RSASigner struct {
+	activeKeyID string
+	privateKey  *rsa.PrivateKey
+	publicKeys  map[string]*rsa.PublicKey // kid -> key, includes activeKeyID's own public half
+}
+
+// NewRSASigner builds an RSASigner that signs with private under
+// activeKeyID, additionally publishing and trusting the public keys in
+// previous for verification only.
+func NewRSASigner(activeKeyID string, private *rsa.PrivateKey, previous map[string]*rsa.PublicKey) *RSASigner {
+	publicKeys := make(map[string]*rsa.PublicKey, len(previous)+1)
+	for kid, key := range previous {
+		publicKeys[kid] = key
+	}
+	publicKeys[activeKeyID] = &private.PublicKey
+	return &RSASigner{activeKeyID: activeKeyID, privateKey: private, publicKeys: publicKeys}
+}
+
+// NewRSASignerFromEnv loads the active private key from the PEM file named
+// by JWT_PRIVATE_KEY_FILE, keyed as JWT_PRIVATE_KEY_KID (or "rsa-default").
+// A key being rotated out of signing can stay verification-only by pointing
+// JWT_PREVIOUS_PUBLIC_KEY_FILE/JWT_PREVIOUS_PUBLIC_KEY_KID at its public
+// half.
+func NewRSASignerFromEnv() (*RSASigner, error) {
+	keyPath := os.Getenv("JWT_PRIVATE_KEY_FILE")
+	if keyPath == "" {
+		return nil, fmt.Errorf("tokens: JWT_PRIVATE_KEY_FILE is required when JWT_SIGNING_METHOD=RS256")
+	}
+	private, err := loadRSAPrivateKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	activeKeyID := os.Getenv("JWT_PRIVATE_KEY_KID")
+	if activeKeyID == "" {
+		activeKeyID = "rsa-default"
+	}
+
+	previous := map[string]*rsa.PublicKey{}
+	if prevKeyID := os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_KID"); prevKeyID != "" {
+		if prevPath := os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_FILE"); prevPath != "" {
+			prevPublic, err := loadRSAPublicKey(prevPath)
+			if err != nil {
+				return nil, err
+			}
+			previous[prevKeyID] = prevPublic
+		}
+	}
+
+	return NewRSASigner(activeKeyID, private, previous), nil
+}
+
+func (s *RSASigner) KeyID() string { return s.activeKeyID }
+
+func (s *RSASigner) Sign(claims Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claimsToMapClaims(claims))
+	token.Header["kid"] = s.activeKeyID
+	return token.SignedString(s.privateKey)
+}
+
+func (s *RSASigner) Verify(tokenString string) (Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		kid, _ := t.Header["kid"].(string)
+		key, ok := s.publicKeys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return key, nil
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+	return claimsFromMapClaims(claims), nil
+}
+
+// PublicJWKS returns the JSON Web Key Set of every public key this signer
+// currently trusts for verification, so the auth service can publish it at
+// /.well-known/jwks.json for sibling services to fetch.
+func (s *RSASigner) PublicJWKS() []JWK {
+	jwks := make([]JWK, 0, len(s.publicKeys))
+	for kid, key := range s.publicKeys {
+		jwks = append(jwks, JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	return jwks
+}
+
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tokens: reading private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("tokens: no PEM block found in %s", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("tokens: parsing private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("tokens: private key at %s is not RSA", path)
+	}
+	return key, nil
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tokens: reading public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("tokens: no PEM block found in %s", path)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("tokens: parsing public key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("tokens: public key at %s is not RSA", path)
+	}
+	return key, nil
+}
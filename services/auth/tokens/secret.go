@@ -0,0 +1,26 @@
+package tokens
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// secretFromEnv reads a secret from fileEnvVar's file when set (for a
+// secret mounted from disk, e.g. a Kubernetes secret volume, rather than
+// passed as a literal env var), falling back to envVar itself. Returns a
+// nil slice, not an error, when neither is set - callers decide whether
+// that's fatal or has its own default.
+func secretFromEnv(envVar, fileEnvVar string) ([]byte, error) {
+	if path := os.Getenv(fileEnvVar); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("tokens: reading %s: %w", fileEnvVar, err)
+		}
+		return bytes.TrimSpace(data), nil
+	}
+	if v := os.Getenv(envVar); v != "" {
+		return []byte(v), nil
+	}
+	return nil, nil
+}
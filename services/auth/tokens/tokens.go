@@ -0,0 +1,85 @@
+// Package tokens issues and verifies the bearer tokens the auth service
+// hands out, behind a TokenSigner interface so the signing scheme (today a
+// shared HMAC secret, optionally RSA key pairs for real key rotation) can
+// change without touching the handlers or sibling services that consume
+// it.
+package tokens
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims is the auth service's own representation of a token's payload,
+// translated to/from jwt.MapClaims by each TokenSigner implementation.
+type Claims struct {
+	UserID    string
+	Email     string
+	Roles     []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// ErrUnknownKey is returned by Verify when a token's kid header doesn't
+// match any key the signer currently trusts, e.g. it was signed by a key
+// that has since been retired.
+var ErrUnknownKey = errors.New("tokens: unknown signing key")
+
+// TokenSigner issues and verifies bearer tokens. KeyID identifies the key
+// currently used to sign new tokens; Verify may additionally accept tokens
+// signed by other keys the implementation still trusts (kept around during
+// a rotation) as long as their kid is recognized.
+type TokenSigner interface {
+	Sign(claims Claims) (string, error)
+	Verify(tokenString string) (Claims, error)
+	KeyID() string
+}
+
+// claimsToMapClaims builds the jwt.MapClaims a TokenSigner signs, matching
+// the field names sibling services' middleware already reads.
+func claimsToMapClaims(c Claims) jwt.MapClaims {
+	roles := c.Roles
+	if len(roles) == 0 {
+		roles = []string{"user"}
+	}
+	mc := jwt.MapClaims{
+		"user_id": c.UserID,
+		"email":   c.Email,
+		"roles":   roles,
+	}
+	if !c.IssuedAt.IsZero() {
+		mc["iat"] = c.IssuedAt.Unix()
+	}
+	if !c.ExpiresAt.IsZero() {
+		mc["exp"] = c.ExpiresAt.Unix()
+	}
+	return mc
+}
+
+// claimsFromMapClaims is the inverse of claimsToMapClaims, used once a
+// token's signature has already been verified.
+func claimsFromMapClaims(mc jwt.MapClaims) Claims {
+	var c Claims
+	if v, ok := mc["user_id"].(string); ok {
+		c.UserID = v
+	}
+	if v, ok := mc["email"].(string); ok {
+		c.Email = v
+	}
+	if raw, ok := mc["roles"].([]interface{}); ok {
+		for _, r := range raw {
+			if s, ok := r.(string); ok {
+				c.Roles = append(c.Roles, s)
+			}
+		}
+	}
+	if iat, err := mc.GetIssuedAt(); err == nil && iat != nil {
+		c.IssuedAt = iat.Time
+	}
+	if exp, err := mc.GetExpirationTime(); err == nil && exp != nil {
+		c.ExpiresAt = exp.Time
+	}
+	return c
+}
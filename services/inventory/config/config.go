@@ -10,8 +10,11 @@ type Config struct {
 	Logs        LogConfig
 	DB          PostgresConfig
 	Port        string
+	GRPCPort    string
 	CorsAllowed string
 	RabbitMqURL string
+	SeedPath    string // when set, products are (re)seeded from this JSON file on startup
+	AdminSecret string // shared secret required by the /admin/reseed endpoint
 }
 
 type LogConfig struct {
@@ -29,7 +32,8 @@ func LoadConfig() (*Config, error) {
 	}
 
 	cfg := &Config{
-		Port: os.Getenv("PORT"),
+		Port:     os.Getenv("PORT"),
+		GRPCPort: os.Getenv("GRPC_PORT"),
 		Logs: LogConfig{
 			Style: os.Getenv("LOG_STYLE"),
 			Level: os.Getenv("LOG_LEVEL"),
@@ -38,6 +42,8 @@ func LoadConfig() (*Config, error) {
 			DBString: dbString,
 		},
 		CorsAllowed: os.Getenv("CORS_ALLOWED_ORIGIN"),
+		SeedPath:    os.Getenv("SEED_PATH"),
+		AdminSecret: os.Getenv("ADMIN_SECRET"),
 	}
 
 	return cfg, nil
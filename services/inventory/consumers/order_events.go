@@ -0,0 +1,110 @@
+// Package consumers holds the inventory service's RabbitMQ consumers for
+// events published by other services' outbox publishers. Each consumer is
+// idempotent: it records the message's event ID in processed_events before
+// acting, so a redelivered message (at-least-once delivery, consumer
+// restart, broker requeue) is a no-op instead of double-applying an effect.
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// orderCreatedEvent mirrors the payload orders.outbox publishes for
+// order.created; only the fields inventory needs to remember are decoded.
+type orderCreatedEvent struct {
+	OrderID string `json:"order_id"`
+	Items   []struct {
+		ProductID string `json:"product_id"`
+		Quantity  int    `json:"quantity"`
+	} `json:"items"`
+}
+
+// OrderEventsConsumer records which products (and quantities) were reserved
+// for an order, purely from order.created events, so PaymentFailedConsumer
+// can later release the right quantity without calling back into orders.
+type OrderEventsConsumer struct {
+	db *pgxpool.Pool
+}
+
+// NewOrderEventsConsumer returns a consumer that persists reservation
+// bookkeeping from order.created events into order_reservations.
+func NewOrderEventsConsumer(db *pgxpool.Pool) *OrderEventsConsumer {
+	return &OrderEventsConsumer{db: db}
+}
+
+// Consume runs until deliveries closes (the channel shut down or ctx done),
+// acking each message once it has been durably recorded (or ignored as a
+// duplicate).
+func (c *OrderEventsConsumer) Consume(ctx context.Context, deliveries <-chan amqp.Delivery) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d, ok := <-deliveries:
+			if !ok {
+				return
+			}
+			if d.RoutingKey != "order.created" {
+				d.Ack(false)
+				continue
+			}
+			if err := c.handle(ctx, d); err != nil {
+				slog.Error("consumers: failed to handle order.created", "error", err)
+				d.Nack(false, true)
+				continue
+			}
+			d.Ack(false)
+		}
+	}
+}
+
+func (c *OrderEventsConsumer) handle(ctx context.Context, d amqp.Delivery) error {
+	var event orderCreatedEvent
+	if err := json.Unmarshal(d.Body, &event); err != nil {
+		return err
+	}
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if processed, err := alreadyProcessed(ctx, tx, d.MessageId); err != nil {
+		return err
+	} else if processed {
+		return tx.Commit(ctx)
+	}
+
+	for _, item := range event.Items {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO order_reservations (order_id, product_id, quantity) VALUES ($1, $2, $3)`,
+			event.OrderID, item.ProductID, item.Quantity,
+		); err != nil {
+			return err
+		}
+	}
+
+	if err := markProcessed(ctx, tx, d.MessageId); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func alreadyProcessed(ctx context.Context, tx pgx.Tx, eventID string) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM processed_events WHERE event_id = $1)`, eventID).Scan(&exists)
+	return exists, err
+}
+
+func markProcessed(ctx context.Context, tx pgx.Tx, eventID string) error {
+	_, err := tx.Exec(ctx, `INSERT INTO processed_events (event_id) VALUES ($1) ON CONFLICT DO NOTHING`, eventID)
+	return err
+}
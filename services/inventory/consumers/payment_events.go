@@ -0,0 +1,123 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// paymentFailedEvent mirrors the payload payment.outbox publishes for
+// payment.failed; inventory only needs the order ID to look up what it
+// reserved.
+type paymentFailedEvent struct {
+	OrderID string `json:"order_id"`
+}
+
+// PaymentFailedConsumer auto-releases a product's reserved quantity when the
+// order it was reserved for ends up with a failed payment, so a declined
+// card doesn't leave stock stuck in "reserved" until someone notices.
+type PaymentFailedConsumer struct {
+	db *pgxpool.Pool
+}
+
+// NewPaymentFailedConsumer returns a consumer that releases reservations
+// recorded by OrderEventsConsumer when the owning order's payment fails.
+func NewPaymentFailedConsumer(db *pgxpool.Pool) *PaymentFailedConsumer {
+	return &PaymentFailedConsumer{db: db}
+}
+
+// Consume runs until deliveries closes (the channel shut down or ctx done).
+func (c *PaymentFailedConsumer) Consume(ctx context.Context, deliveries <-chan amqp.Delivery) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d, ok := <-deliveries:
+			if !ok {
+				return
+			}
+			if d.RoutingKey != "payment.failed" {
+				d.Ack(false)
+				continue
+			}
+			if err := c.handle(ctx, d); err != nil {
+				slog.Error("consumers: failed to handle payment.failed", "error", err)
+				d.Nack(false, true)
+				continue
+			}
+			d.Ack(false)
+		}
+	}
+}
+
+func (c *PaymentFailedConsumer) handle(ctx context.Context, d amqp.Delivery) error {
+	var event paymentFailedEvent
+	if err := json.Unmarshal(d.Body, &event); err != nil {
+		return err
+	}
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if processed, err := alreadyProcessed(ctx, tx, d.MessageId); err != nil {
+		return err
+	} else if processed {
+		return tx.Commit(ctx)
+	}
+
+	reservations, err := reservationsForOrder(ctx, tx, event.OrderID)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range reservations {
+		if _, err := tx.Exec(ctx,
+			`UPDATE products
+			 SET reserved = reserved - $1,
+			     available_quantity = available_quantity + $1,
+			     version = version + 1,
+			     updated_at = NOW()
+			 WHERE id = $2 AND reserved >= $1`,
+			r.Quantity, r.ProductID,
+		); err != nil {
+			return err
+		}
+	}
+
+	if err := markProcessed(ctx, tx, d.MessageId); err != nil {
+		return err
+	}
+
+	slog.Info("consumers: released reservations for failed payment", "order_id", event.OrderID, "products", len(reservations))
+	return tx.Commit(ctx)
+}
+
+type reservedItem struct {
+	ProductID string
+	Quantity  int
+}
+
+func reservationsForOrder(ctx context.Context, tx pgx.Tx, orderID string) ([]reservedItem, error) {
+	rows, err := tx.Query(ctx, `SELECT product_id, quantity FROM order_reservations WHERE order_id = $1`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []reservedItem
+	for rows.Next() {
+		var r reservedItem
+		if err := rows.Scan(&r.ProductID, &r.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, r)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,57 @@
+// Package errors defines the structured error envelope the inventory
+// service returns for business-level rejections (insufficient stock, an
+// unknown product), as opposed to transport failures like a 500 or a
+// connection error. Callers branch on Code instead of pattern-matching a
+// plain-text message.
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Codes the inventory service returns in Envelope.Code.
+const (
+	CodeInsufficientStock = "INSUFFICIENT_STOCK"
+	CodeProductNotFound   = "PRODUCT_NOT_FOUND"
+)
+
+// Details carries the extra context a Code needs for the caller to decide
+// what to do next - retry with a smaller quantity, split the order, etc.
+type Details struct {
+	ProductID string `json:"product_id,omitempty"`
+	Available int    `json:"available,omitempty"`
+	Requested int    `json:"requested,omitempty"`
+}
+
+// Envelope is the JSON body a business-level rejection is returned with.
+type Envelope struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Details Details `json:"details,omitempty"`
+}
+
+// InsufficientStock builds the envelope for CodeInsufficientStock.
+func InsufficientStock(productID string, available, requested int) Envelope {
+	return Envelope{
+		Code:    CodeInsufficientStock,
+		Message: "insufficient stock available",
+		Details: Details{ProductID: productID, Available: available, Requested: requested},
+	}
+}
+
+// ProductNotFound builds the envelope for CodeProductNotFound.
+func ProductNotFound(productID string) Envelope {
+	return Envelope{
+		Code:    CodeProductNotFound,
+		Message: "product not found",
+		Details: Details{ProductID: productID},
+	}
+}
+
+// Write responds with env as the JSON body at the given status code.
+func Write(w http.ResponseWriter, status int, env Envelope) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(env)
+}
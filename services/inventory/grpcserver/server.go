@@ -0,0 +1,219 @@
+// Package grpcserver exposes InventoryHandler's business logic over gRPC,
+// running alongside (not instead of) the chi HTTP server. Run `make proto`
+// to (re)generate the inventorypb stubs this package depends on.
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/handlers"
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/proto/inventorypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the generated InventoryServiceServer interface with its
+// own queries against the same *pgxpool.Pool the HTTP handlers use, so both
+// transports see identical data; it shares only the handlers.Product type
+// with *handlers.InventoryHandler, not its methods.
+type Server struct {
+	inventorypb.UnimplementedInventoryServiceServer
+	db *pgxpool.Pool
+}
+
+// New builds a gRPC InventoryService server backed by the same database pool
+// as the HTTP handlers.
+func New(db *pgxpool.Pool) *Server {
+	return &Server{db: db}
+}
+
+// Register wires the InventoryService and standard grpc.health.v1 health
+// service onto grpcServer, mirroring the RED/OTEL middleware the HTTP
+// transport applies via interceptors installed by the caller.
+func (s *Server) Register(grpcServer *grpc.Server) {
+	inventorypb.RegisterInventoryServiceServer(grpcServer, s)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("inventory.InventoryService", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+}
+
+// Serve starts grpcServer on addr and blocks until it stops or ctx is done.
+func Serve(ctx context.Context, grpcServer *grpc.Server, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- grpcServer.Serve(lis) }()
+
+	select {
+	case <-ctx.Done():
+		grpcServer.GracefulStop()
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (s *Server) CreateProduct(ctx context.Context, req *inventorypb.CreateProductRequest) (*inventorypb.Product, error) {
+	if req.GetTitle() == "" || req.GetPrice() <= 0 || req.GetAvailableQuantity() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "title, valid price, and available quantity are required")
+	}
+
+	var p handlers.Product
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO products (title, price, available_quantity, reserved)
+		 VALUES ($1, $2, $3, 0)
+		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
+		req.GetTitle(), req.GetPrice(), req.GetAvailableQuantity(),
+	).Scan(&p.ID, &p.Title, &p.Price, &p.AvailableQuantity, &p.Reserved, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		slog.Error("grpc CreateProduct failed", "error", err)
+		return nil, status.Error(codes.Internal, "failed to create product")
+	}
+
+	return toProto(p), nil
+}
+
+func (s *Server) GetProduct(ctx context.Context, req *inventorypb.GetProductRequest) (*inventorypb.Product, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "product id is required")
+	}
+
+	var p handlers.Product
+	err := s.db.QueryRow(ctx,
+		`SELECT id, title, price, available_quantity, reserved, created_at, updated_at
+		 FROM products WHERE id = $1`,
+		req.GetId(),
+	).Scan(&p.ID, &p.Title, &p.Price, &p.AvailableQuantity, &p.Reserved, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+
+	return toProto(p), nil
+}
+
+func (s *Server) ListProducts(ctx context.Context, _ *inventorypb.ListProductsRequest) (*inventorypb.ListProductsResponse, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT id, title, price, available_quantity, reserved, created_at, updated_at
+		 FROM products ORDER BY created_at DESC`,
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list products")
+	}
+	defer rows.Close()
+
+	resp := &inventorypb.ListProductsResponse{}
+	for rows.Next() {
+		var p handlers.Product
+		if err := rows.Scan(&p.ID, &p.Title, &p.Price, &p.AvailableQuantity, &p.Reserved, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, status.Error(codes.Internal, "failed to scan product")
+		}
+		resp.Products = append(resp.Products, toProto(p))
+	}
+	return resp, rows.Err()
+}
+
+func (s *Server) UpdateProduct(ctx context.Context, req *inventorypb.UpdateProductRequest) (*inventorypb.Product, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "product id is required")
+	}
+
+	var p handlers.Product
+	err := s.db.QueryRow(ctx,
+		`UPDATE products
+		 SET title = COALESCE(NULLIF($1, ''), title),
+		     price = COALESCE(NULLIF($2, 0), price),
+		     available_quantity = COALESCE(NULLIF($3, -1), available_quantity),
+		     updated_at = NOW()
+		 WHERE id = $4
+		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
+		req.GetTitle(), req.GetPrice(), req.GetAvailableQuantity(), req.GetId(),
+	).Scan(&p.ID, &p.Title, &p.Price, &p.AvailableQuantity, &p.Reserved, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to update product")
+	}
+
+	return toProto(p), nil
+}
+
+func (s *Server) DeleteProduct(ctx context.Context, req *inventorypb.DeleteProductRequest) (*inventorypb.DeleteProductResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "product id is required")
+	}
+
+	result, err := s.db.Exec(ctx, `DELETE FROM products WHERE id = $1`, req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to delete product")
+	}
+	if result.RowsAffected() == 0 {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+
+	return &inventorypb.DeleteProductResponse{}, nil
+}
+
+func (s *Server) ReserveProduct(ctx context.Context, req *inventorypb.ReserveProductRequest) (*inventorypb.Product, error) {
+	if req.GetQuantity() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "quantity must be greater than 0")
+	}
+
+	var p handlers.Product
+	err := s.db.QueryRow(ctx,
+		`UPDATE products
+		 SET reserved = reserved + $1,
+		     available_quantity = available_quantity - $1,
+		     updated_at = NOW()
+		 WHERE id = $2 AND available_quantity >= $1
+		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
+		req.GetQuantity(), req.GetProductId(),
+	).Scan(&p.ID, &p.Title, &p.Price, &p.AvailableQuantity, &p.Reserved, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "insufficient quantity or product not found")
+	}
+
+	return toProto(p), nil
+}
+
+func (s *Server) ReleaseProduct(ctx context.Context, req *inventorypb.ReleaseProductRequest) (*inventorypb.Product, error) {
+	if req.GetQuantity() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "quantity must be greater than 0")
+	}
+
+	var p handlers.Product
+	err := s.db.QueryRow(ctx,
+		`UPDATE products
+		 SET reserved = reserved - $1,
+		     available_quantity = available_quantity + $1,
+		     updated_at = NOW()
+		 WHERE id = $2 AND reserved >= $1
+		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
+		req.GetQuantity(), req.GetProductId(),
+	).Scan(&p.ID, &p.Title, &p.Price, &p.AvailableQuantity, &p.Reserved, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "insufficient reserved quantity or product not found")
+	}
+
+	return toProto(p), nil
+}
+
+func toProto(p handlers.Product) *inventorypb.Product {
+	return &inventorypb.Product{
+		Id:                p.ID,
+		Title:             p.Title,
+		Price:             p.Price,
+		AvailableQuantity: int32(p.AvailableQuantity),
+		Reserved:          int32(p.Reserved),
+		CreatedAt:         timestamppb.New(p.CreatedAt),
+		UpdatedAt:         timestamppb.New(p.UpdatedAt),
+	}
+}
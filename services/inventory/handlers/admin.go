@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/seeds"
+)
+
+type ReseedResponse struct {
+	ProductsSeeded int `json:"products_seeded"`
+}
+
+// Reseed re-applies the SEED_PATH fixture on demand, so integration tests
+// can reset product state between runs without restarting the service. It
+// is guarded by a shared secret since it mutates data outside normal
+// request flow.
+func (h *InventoryHandler) Reseed(w http.ResponseWriter, r *http.Request) {
+	if h.adminSecret == "" || r.Header.Get("X-Admin-Secret") != h.adminSecret {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if h.seedPath == "" {
+		http.Error(w, "SEED_PATH is not configured", http.StatusNotImplemented)
+		return
+	}
+
+	count, err := seeds.Load(r.Context(), h.db, h.seedPath)
+	if err != nil {
+		http.Error(w, "Failed to reseed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ReseedResponse{ProductsSeeded: count})
+}
@@ -4,14 +4,18 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/outbox"
 )
 
 type InventoryHandler struct {
-	db *pgxpool.Pool
+	db          *pgxpool.Pool
+	seedPath    string
+	adminSecret string
+	outboxStore *outbox.Store
 }
 
-func New(conn *pgxpool.Pool) *InventoryHandler {
-	return &InventoryHandler{db: conn}
+func New(conn *pgxpool.Pool, seedPath, adminSecret string, outboxStore *outbox.Store) *InventoryHandler {
+	return &InventoryHandler{db: conn, seedPath: seedPath, adminSecret: adminSecret, outboxStore: outboxStore}
 }
 
 type Product struct {
@@ -20,6 +24,7 @@ type Product struct {
 	Price             float64   `json:"price"`
 	AvailableQuantity int       `json:"available_quantity"`
 	Reserved          int       `json:"reserved"`
+	Version           int       `json:"version"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
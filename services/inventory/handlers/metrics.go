@@ -0,0 +1,18 @@
+package handlers
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// versionConflictsTotal counts optimistic concurrency conflicts detected
+// while applying an If-Match/expected_version update, reserve, or release,
+// so contention on hot products is visible without digging through traces.
+var versionConflictsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "inventory_version_conflicts_total",
+		Help: "Total optimistic concurrency conflicts on product row updates, labeled by operation.",
+	},
+	[]string{"operation"},
+)
+
+func init() {
+	prometheus.MustRegister(versionConflictsTotal)
+}
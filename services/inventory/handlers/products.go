@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	invErrors "github.com/rafidoth/train-ticket-booking-microservice/inventory/errors"
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/httpx"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -20,10 +24,52 @@ type UpdateProductRequest struct {
 	Title             string  `json:"title,omitempty"`
 	Price             float64 `json:"price,omitempty"`
 	AvailableQuantity int     `json:"available_quantity,omitempty"`
+	ExpectedVersion   *int    `json:"expected_version,omitempty"`
 }
 
 type ReserveProductRequest struct {
-	Quantity int `json:"quantity"`
+	Quantity        int  `json:"quantity"`
+	ExpectedVersion *int `json:"expected_version,omitempty"`
+}
+
+// inventoryReservationEvent is the payload published for the
+// inventory.reserved and inventory.released outbox events.
+type inventoryReservationEvent struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// VersionConflictResponse is returned with a 409 when an If-Match/
+// expected_version check fails, so the caller can refetch the product and
+// retry against its current version.
+type VersionConflictResponse struct {
+	Error          string `json:"error"`
+	CurrentVersion int    `json:"current_version"`
+}
+
+// expectedVersionFromRequest resolves the caller's expected row version,
+// preferring the If-Match header (the standard HTTP mechanism for optimistic
+// concurrency) over the expected_version body field.
+func expectedVersionFromRequest(r *http.Request, bodyVersion *int) (*int, error) {
+	if raw := r.Header.Get("If-Match"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &v, nil
+	}
+	return bodyVersion, nil
+}
+
+// writeVersionConflict responds 409 with the row's current version so the
+// caller can refetch and retry instead of blindly resubmitting.
+func writeVersionConflict(w http.ResponseWriter, currentVersion int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(VersionConflictResponse{
+		Error:          "version_conflict",
+		CurrentVersion: currentVersion,
+	})
 }
 
 // CreateProduct creates a new product
@@ -43,11 +89,22 @@ func (h *InventoryHandler) CreateProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if req.Title == "" || req.Price <= 0 || req.AvailableQuantity < 0 {
+	var violations []httpx.FieldError
+	if req.Title == "" {
+		violations = append(violations, httpx.FieldError{Field: "title", Message: "is required"})
+	}
+	if req.Price <= 0 {
+		violations = append(violations, httpx.FieldError{Field: "price", Message: "must be > 0"})
+	}
+	if req.AvailableQuantity < 0 {
+		violations = append(violations, httpx.FieldError{Field: "available_quantity", Message: "must be >= 0"})
+	}
+	if len(violations) > 0 {
+		validateSpan.SetAttributes(attribute.StringSlice("validation.fields", httpx.FieldNames(violations)))
 		validateSpan.SetStatus(codes.Error, "missing or invalid required fields")
 		validateSpan.End()
 
-		http.Error(w, "Title, valid price, and available quantity are required", http.StatusBadRequest)
+		httpx.ValidationErrors(w, violations)
 		return
 	}
 
@@ -67,11 +124,11 @@ func (h *InventoryHandler) CreateProduct(w http.ResponseWriter, r *http.Request)
 	var product Product
 	err := h.db.QueryRow(
 		ctx,
-		`INSERT INTO products (title, price, available_quantity, reserved)
-		 VALUES ($1, $2, $3, 0)
-		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
+		`INSERT INTO products (title, price, available_quantity, reserved, version)
+		 VALUES ($1, $2, $3, 0, 0)
+		 RETURNING id, title, price, available_quantity, reserved, version, created_at, updated_at`,
 		req.Title, req.Price, req.AvailableQuantity,
-	).Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.CreatedAt, &product.UpdatedAt)
+	).Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.Version, &product.CreatedAt, &product.UpdatedAt)
 
 	if err != nil {
 		dbSpan.RecordError(err)
@@ -104,7 +161,7 @@ func (h *InventoryHandler) GetAllProducts(w http.ResponseWriter, r *http.Request
 
 	rows, err := h.db.Query(
 		ctx,
-		`SELECT id, title, price, available_quantity, reserved, created_at, updated_at
+		`SELECT id, title, price, available_quantity, reserved, version, created_at, updated_at
 		 FROM products
 		 ORDER BY created_at DESC`,
 	)
@@ -121,7 +178,7 @@ func (h *InventoryHandler) GetAllProducts(w http.ResponseWriter, r *http.Request
 	var products []Product
 	for rows.Next() {
 		var product Product
-		err := rows.Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.CreatedAt, &product.UpdatedAt)
+		err := rows.Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.Version, &product.CreatedAt, &product.UpdatedAt)
 		if err != nil {
 			dbSpan.RecordError(err)
 			dbSpan.SetStatus(codes.Error, "row scan failed")
@@ -162,11 +219,11 @@ func (h *InventoryHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	var product Product
 	err := h.db.QueryRow(
 		ctx,
-		`SELECT id, title, price, available_quantity, reserved, created_at, updated_at
+		`SELECT id, title, price, available_quantity, reserved, version, created_at, updated_at
 		 FROM products
 		 WHERE id = $1`,
 		productID,
-	).Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.CreatedAt, &product.UpdatedAt)
+	).Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.Version, &product.CreatedAt, &product.UpdatedAt)
 
 	if err != nil {
 		dbSpan.RecordError(err)
@@ -206,6 +263,16 @@ func (h *InventoryHandler) UpdateProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	expectedVersion, err := expectedVersionFromRequest(r, req.ExpectedVersion)
+	if err != nil {
+		validateSpan.SetAttributes(attribute.StringSlice("validation.fields", []string{"if_match"}))
+		validateSpan.SetStatus(codes.Error, "invalid If-Match version")
+		validateSpan.End()
+
+		httpx.ValidationError(w, "if_match", "must be an integer version")
+		return
+	}
+
 	validateSpan.SetStatus(codes.Ok, "validation passed")
 	validateSpan.End()
 
@@ -216,22 +283,43 @@ func (h *InventoryHandler) UpdateProduct(w http.ResponseWriter, r *http.Request)
 		attribute.String("db.table", "products"),
 		attribute.String("product.id", productID),
 	)
+	if expectedVersion != nil {
+		dbSpan.SetAttributes(attribute.Int("product.version.expected", *expectedVersion))
+	}
 
-	var product Product
-	err := h.db.QueryRow(
-		ctx,
-		`UPDATE products
+	query := `UPDATE products
 		 SET title = COALESCE(NULLIF($1, ''), title),
 		     price = COALESCE(NULLIF($2, 0), price),
 		     available_quantity = COALESCE(NULLIF($3, -1), available_quantity),
+		     version = version + 1,
 		     updated_at = NOW()
-		 WHERE id = $4
-		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
-		req.Title, req.Price, req.AvailableQuantity, productID,
-	).Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.CreatedAt, &product.UpdatedAt)
+		 WHERE id = $4`
+	args := []any{req.Title, req.Price, req.AvailableQuantity, productID}
+	if expectedVersion != nil {
+		query += " AND version = $5"
+		args = append(args, *expectedVersion)
+	}
+	query += " RETURNING id, title, price, available_quantity, reserved, version, created_at, updated_at"
+
+	var product Product
+	err = h.db.QueryRow(ctx, query, args...).
+		Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.Version, &product.CreatedAt, &product.UpdatedAt)
 
 	if err != nil {
 		dbSpan.RecordError(err)
+
+		if expectedVersion != nil {
+			if currentVersion, ok := h.currentVersion(ctx, productID); ok && currentVersion != *expectedVersion {
+				dbSpan.SetAttributes(attribute.Int("product.version.actual", currentVersion))
+				dbSpan.SetStatus(codes.Error, "version conflict")
+				dbSpan.End()
+
+				versionConflictsTotal.WithLabelValues("update").Inc()
+				writeVersionConflict(w, currentVersion)
+				return
+			}
+		}
+
 		dbSpan.SetStatus(codes.Error, "database update failed")
 		dbSpan.End()
 
@@ -315,11 +403,22 @@ func (h *InventoryHandler) ReserveProduct(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	var violations []httpx.FieldError
 	if req.Quantity <= 0 {
-		validateSpan.SetStatus(codes.Error, "invalid quantity")
+		violations = append(violations, httpx.FieldError{Field: "quantity", Message: "must be greater than 0"})
+	}
+
+	expectedVersion, verErr := expectedVersionFromRequest(r, req.ExpectedVersion)
+	if verErr != nil {
+		violations = append(violations, httpx.FieldError{Field: "if_match", Message: "must be an integer version"})
+	}
+
+	if len(violations) > 0 {
+		validateSpan.SetAttributes(attribute.StringSlice("validation.fields", httpx.FieldNames(violations)))
+		validateSpan.SetStatus(codes.Error, "invalid request")
 		validateSpan.End()
 
-		http.Error(w, "Quantity must be greater than 0", http.StatusBadRequest)
+		httpx.ValidationErrors(w, violations)
 		return
 	}
 
@@ -334,25 +433,88 @@ func (h *InventoryHandler) ReserveProduct(w http.ResponseWriter, r *http.Request
 		attribute.String("product.id", productID),
 		attribute.Int("quantity", req.Quantity),
 	)
+	if expectedVersion != nil {
+		dbSpan.SetAttributes(attribute.Int("product.version.expected", *expectedVersion))
+	}
 
-	var product Product
-	err := h.db.QueryRow(
-		ctx,
-		`UPDATE products
+	query := `UPDATE products
 		 SET reserved = reserved + $1,
 		     available_quantity = available_quantity - $1,
+		     version = version + 1,
 		     updated_at = NOW()
-		 WHERE id = $2 AND available_quantity >= $1
-		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
-		req.Quantity, productID,
-	).Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.CreatedAt, &product.UpdatedAt)
+		 WHERE id = $2 AND available_quantity >= $1`
+	args := []any{req.Quantity, productID}
+	if expectedVersion != nil {
+		query += " AND version = $3"
+		args = append(args, *expectedVersion)
+	}
+	query += " RETURNING id, title, price, available_quantity, reserved, version, created_at, updated_at"
 
+	tx, err := h.db.Begin(ctx)
 	if err != nil {
 		dbSpan.RecordError(err)
-		dbSpan.SetStatus(codes.Error, "insufficient quantity or product not found")
+		dbSpan.SetStatus(codes.Error, "failed to begin transaction")
+		dbSpan.End()
+
+		http.Error(w, "Failed to reserve product", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var product Product
+	err = tx.QueryRow(ctx, query, args...).
+		Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.Version, &product.CreatedAt, &product.UpdatedAt)
+
+	if err != nil {
+		dbSpan.RecordError(err)
+
+		if expectedVersion != nil {
+			if currentVersion, ok := h.currentVersion(ctx, productID); ok && currentVersion != *expectedVersion {
+				dbSpan.SetAttributes(attribute.Int("product.version.actual", currentVersion))
+				dbSpan.SetStatus(codes.Error, "version conflict")
+				dbSpan.End()
+
+				versionConflictsTotal.WithLabelValues("reserve").Inc()
+				writeVersionConflict(w, currentVersion)
+				return
+			}
+		}
+
+		if current, ok := h.lookupProduct(ctx, productID); ok {
+			dbSpan.SetStatus(codes.Error, "insufficient stock")
+			dbSpan.End()
+
+			invErrors.Write(w, http.StatusConflict, invErrors.InsufficientStock(productID, current.AvailableQuantity, req.Quantity))
+			return
+		}
+
+		dbSpan.SetStatus(codes.Error, "product not found")
+		dbSpan.End()
+
+		invErrors.Write(w, http.StatusNotFound, invErrors.ProductNotFound(productID))
+		return
+	}
+
+	if h.outboxStore != nil {
+		if err := h.outboxStore.Enqueue(ctx, tx, product.ID, "inventory.reserved", inventoryReservationEvent{
+			ProductID: product.ID,
+			Quantity:  req.Quantity,
+		}, dbSpan); err != nil {
+			dbSpan.RecordError(err)
+			dbSpan.SetStatus(codes.Error, "failed to enqueue outbox event")
+			dbSpan.End()
+
+			http.Error(w, "Failed to reserve product", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, "failed to commit transaction")
 		dbSpan.End()
 
-		http.Error(w, "Insufficient quantity or product not found", http.StatusBadRequest)
+		http.Error(w, "Failed to reserve product", http.StatusInternalServerError)
 		return
 	}
 
@@ -385,11 +547,22 @@ func (h *InventoryHandler) ReleaseProduct(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	var violations []httpx.FieldError
 	if req.Quantity <= 0 {
-		validateSpan.SetStatus(codes.Error, "invalid quantity")
+		violations = append(violations, httpx.FieldError{Field: "quantity", Message: "must be greater than 0"})
+	}
+
+	expectedVersion, verErr := expectedVersionFromRequest(r, req.ExpectedVersion)
+	if verErr != nil {
+		violations = append(violations, httpx.FieldError{Field: "if_match", Message: "must be an integer version"})
+	}
+
+	if len(violations) > 0 {
+		validateSpan.SetAttributes(attribute.StringSlice("validation.fields", httpx.FieldNames(violations)))
+		validateSpan.SetStatus(codes.Error, "invalid request")
 		validateSpan.End()
 
-		http.Error(w, "Quantity must be greater than 0", http.StatusBadRequest)
+		httpx.ValidationErrors(w, violations)
 		return
 	}
 
@@ -404,21 +577,53 @@ func (h *InventoryHandler) ReleaseProduct(w http.ResponseWriter, r *http.Request
 		attribute.String("product.id", productID),
 		attribute.Int("quantity", req.Quantity),
 	)
+	if expectedVersion != nil {
+		dbSpan.SetAttributes(attribute.Int("product.version.expected", *expectedVersion))
+	}
 
-	var product Product
-	err := h.db.QueryRow(
-		ctx,
-		`UPDATE products
+	query := `UPDATE products
 		 SET reserved = reserved - $1,
 		     available_quantity = available_quantity + $1,
+		     version = version + 1,
 		     updated_at = NOW()
-		 WHERE id = $2 AND reserved >= $1
-		 RETURNING id, title, price, available_quantity, reserved, created_at, updated_at`,
-		req.Quantity, productID,
-	).Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.CreatedAt, &product.UpdatedAt)
+		 WHERE id = $2 AND reserved >= $1`
+	args := []any{req.Quantity, productID}
+	if expectedVersion != nil {
+		query += " AND version = $3"
+		args = append(args, *expectedVersion)
+	}
+	query += " RETURNING id, title, price, available_quantity, reserved, version, created_at, updated_at"
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, "failed to begin transaction")
+		dbSpan.End()
+
+		http.Error(w, "Failed to release product", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var product Product
+	err = tx.QueryRow(ctx, query, args...).
+		Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.Version, &product.CreatedAt, &product.UpdatedAt)
 
 	if err != nil {
 		dbSpan.RecordError(err)
+
+		if expectedVersion != nil {
+			if currentVersion, ok := h.currentVersion(ctx, productID); ok && currentVersion != *expectedVersion {
+				dbSpan.SetAttributes(attribute.Int("product.version.actual", currentVersion))
+				dbSpan.SetStatus(codes.Error, "version conflict")
+				dbSpan.End()
+
+				versionConflictsTotal.WithLabelValues("release").Inc()
+				writeVersionConflict(w, currentVersion)
+				return
+			}
+		}
+
 		dbSpan.SetStatus(codes.Error, "insufficient reserved quantity or product not found")
 		dbSpan.End()
 
@@ -426,9 +631,57 @@ func (h *InventoryHandler) ReleaseProduct(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if h.outboxStore != nil {
+		if err := h.outboxStore.Enqueue(ctx, tx, product.ID, "inventory.released", inventoryReservationEvent{
+			ProductID: product.ID,
+			Quantity:  req.Quantity,
+		}, dbSpan); err != nil {
+			dbSpan.RecordError(err)
+			dbSpan.SetStatus(codes.Error, "failed to enqueue outbox event")
+			dbSpan.End()
+
+			http.Error(w, "Failed to release product", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, "failed to commit transaction")
+		dbSpan.End()
+
+		http.Error(w, "Failed to release product", http.StatusInternalServerError)
+		return
+	}
+
 	dbSpan.SetStatus(codes.Ok, "product released")
 	dbSpan.End()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(product)
 }
+
+// currentVersion looks up a product's current row version so a failed
+// conditional update can be attributed to a version conflict (stale caller)
+// rather than insufficient stock or a missing product.
+func (h *InventoryHandler) currentVersion(ctx context.Context, productID string) (int, bool) {
+	var version int
+	if err := h.db.QueryRow(ctx, `SELECT version FROM products WHERE id = $1`, productID).Scan(&version); err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// lookupProduct fetches a product's current row so a failed conditional
+// reserve/release can be attributed to insufficient stock (product exists,
+// quantity too low) rather than a missing product. Returns ok=false if no
+// row matches productID.
+func (h *InventoryHandler) lookupProduct(ctx context.Context, productID string) (Product, bool) {
+	var product Product
+	err := h.db.QueryRow(ctx, `SELECT id, title, price, available_quantity, reserved, version, created_at, updated_at FROM products WHERE id = $1`, productID).
+		Scan(&product.ID, &product.Title, &product.Price, &product.AvailableQuantity, &product.Reserved, &product.Version, &product.CreatedAt, &product.UpdatedAt)
+	if err != nil {
+		return Product{}, false
+	}
+	return product, true
+}
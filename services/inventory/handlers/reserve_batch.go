@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type BatchReserveItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type BatchReserveRequest struct {
+	Items []BatchReserveItem `json:"items"`
+}
+
+type BatchReserveResponse struct {
+	Products []Product `json:"products"`
+}
+
+// BatchReservationFailure is returned when any line of a batch reservation
+// fails; Failures maps product ID to the reason that line was rejected.
+// The whole batch is rolled back, so a caller never has to reconcile a
+// partially-applied reservation.
+type BatchReservationFailure struct {
+	Error    string            `json:"error"`
+	Failures map[string]string `json:"failures"`
+}
+
+// ReserveBatch reserves multiple products atomically: either every line
+// succeeds, or none of them do. This backs multi-item orders, where a
+// partial reservation would otherwise leave some products held against an
+// order the caller may decide to reject entirely.
+func (h *InventoryHandler) ReserveBatch(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("inventory-service")
+	ctx, span := tracer.Start(r.Context(), "ReserveBatch")
+	defer span.End()
+
+	var req BatchReserveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Items) == 0 {
+		span.SetStatus(codes.Error, "no items in batch")
+		http.Error(w, "At least one item is required", http.StatusBadRequest)
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", "UPDATE"),
+		attribute.String("db.table", "products"),
+		attribute.Int("batch.size", len(req.Items)),
+	)
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to begin transaction")
+		http.Error(w, "Failed to reserve inventory", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	products := make([]Product, 0, len(req.Items))
+	failures := make(map[string]string)
+
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			failures[item.ProductID] = "quantity must be greater than 0"
+			continue
+		}
+
+		var p Product
+		err := tx.QueryRow(
+			ctx,
+			`UPDATE products
+			 SET reserved = reserved + $1,
+			     available_quantity = available_quantity - $1,
+			     version = version + 1,
+			     updated_at = NOW()
+			 WHERE id = $2 AND available_quantity >= $1
+			 RETURNING id, title, price, available_quantity, reserved, version, created_at, updated_at`,
+			item.Quantity, item.ProductID,
+		).Scan(&p.ID, &p.Title, &p.Price, &p.AvailableQuantity, &p.Reserved, &p.Version, &p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			failures[item.ProductID] = "insufficient quantity or product not found"
+			continue
+		}
+
+		products = append(products, p)
+	}
+
+	if len(failures) > 0 {
+		span.SetAttributes(attribute.Int("batch.failures", len(failures)))
+		span.SetStatus(codes.Error, "batch reservation failed")
+
+		// tx is rolled back by the deferred Rollback since we never Commit.
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(BatchReservationFailure{
+			Error:    "batch_reservation_failed",
+			Failures: failures,
+		})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to commit transaction")
+		http.Error(w, "Failed to reserve inventory", http.StatusInternalServerError)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "batch reserved")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BatchReserveResponse{Products: products})
+}
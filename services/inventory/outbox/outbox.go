@@ -0,0 +1,50 @@
+// Package outbox implements the transactional outbox pattern for publishing
+// domain events to other services over RabbitMQ: a write enqueues its event
+// row in the same database transaction as the business change it describes,
+// and a separate Publisher ships unpublished rows to the broker in the
+// background. That keeps "the order was created" and "an event says the
+// order was created" from ever disagreeing, which publishing before or
+// after the commit (instead of atomically with it) can't guarantee.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Store inserts outbox rows as part of the caller's transaction.
+type Store struct{}
+
+// NewStore returns an outbox Store. It holds no state of its own - every
+// method takes the transaction to write against - so one Store is shared
+// across all callers.
+func NewStore() *Store {
+	return &Store{}
+}
+
+// Enqueue inserts an unpublished outbox row for aggregateID/eventType inside
+// tx. span, if non-nil, supplies the trace/span IDs the Publisher later
+// injects into the AMQP headers so a consumer can link its processing back
+// to the request that produced the event.
+func (s *Store) Enqueue(ctx context.Context, tx pgx.Tx, aggregateID, eventType string, payload any, span trace.Span) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var traceID, spanID string
+	if span != nil {
+		sc := span.SpanContext()
+		traceID, spanID = sc.TraceID().String(), sc.SpanID().String()
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO outbox (aggregate_id, event_type, payload_json, trace_id, span_id)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		aggregateID, eventType, body, traceID, spanID,
+	)
+	return err
+}
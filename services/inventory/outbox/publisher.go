@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	publishBatchSize = 20
+	defaultPollEvery = 2 * time.Second
+)
+
+// pendingRow is one unpublished outbox record read back for dispatch.
+type pendingRow struct {
+	ID          string
+	AggregateID string
+	EventType   string
+	Payload     json.RawMessage
+	TraceID     string
+	SpanID      string
+}
+
+// Publisher polls the outbox table for unpublished rows and ships them to a
+// RabbitMQ exchange, marking each row published only once the broker has
+// accepted it.
+type Publisher struct {
+	db           *pgxpool.Pool
+	channel      *amqp.Channel
+	exchange     string
+	pollInterval time.Duration
+}
+
+// NewPublisher returns a Publisher that ships unpublished outbox rows to
+// exchange over channel, polling db every 2s.
+func NewPublisher(db *pgxpool.Pool, channel *amqp.Channel, exchange string) *Publisher {
+	return &Publisher{db: db, channel: channel, exchange: exchange, pollInterval: defaultPollEvery}
+}
+
+// Start polls until ctx is cancelled. It's meant to be run in its own
+// goroutine, the same way events.Dispatcher is.
+func (p *Publisher) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.runOnce(ctx); err != nil {
+				slog.Error("outbox: publish batch failed", "exchange", p.exchange, "error", err)
+			}
+		}
+	}
+}
+
+func (p *Publisher) runOnce(ctx context.Context) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`SELECT id, aggregate_id, event_type, payload_json, COALESCE(trace_id, ''), COALESCE(span_id, '')
+		 FROM outbox
+		 WHERE published_at IS NULL
+		 ORDER BY created_at
+		 LIMIT $1
+		 FOR UPDATE SKIP LOCKED`,
+		publishBatchSize,
+	)
+	if err != nil {
+		return err
+	}
+
+	var pending []pendingRow
+	for rows.Next() {
+		var row pendingRow
+		if err := rows.Scan(&row.ID, &row.AggregateID, &row.EventType, &row.Payload, &row.TraceID, &row.SpanID); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, row := range pending {
+		if err := p.publish(ctx, row); err != nil {
+			slog.Error("outbox: publish failed, will retry next poll", "id", row.ID, "event_type", row.EventType, "error", err)
+			continue
+		}
+		if _, err := tx.Exec(ctx, `UPDATE outbox SET published_at = NOW() WHERE id = $1`, row.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (p *Publisher) publish(ctx context.Context, row pendingRow) error {
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaderCarrier(headers))
+
+	return p.channel.PublishWithContext(ctx, p.exchange, row.EventType, false, false, amqp.Publishing{
+		ContentType: "application/json",
+		MessageId:   row.ID,
+		Timestamp:   time.Now(),
+		Headers:     headers,
+		Body:        row.Payload,
+	})
+}
+
+// amqpHeaderCarrier adapts amqp.Table to propagation.TextMapCarrier so the
+// current OTel trace context can be injected into AMQP message headers.
+type amqpHeaderCarrier amqp.Table
+
+func (c amqpHeaderCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+func (c amqpHeaderCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c amqpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
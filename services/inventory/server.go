@@ -1,29 +1,47 @@
 package main
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rafidoth/train-ticket-booking-microservice/inventory/config"
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/grpcserver"
 	"github.com/rafidoth/train-ticket-booking-microservice/inventory/handlers"
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/health"
 	"github.com/rafidoth/train-ticket-booking-microservice/inventory/middleware"
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/seeds"
 	"github.com/riandyrn/otelchi"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
 	router  *chi.Mux
 	handler *handlers.InventoryHandler
+	health  *health.Registry
 	cfg     *config.Config
+	db      *pgxpool.Pool
 }
 
-func NewServer(inventoryHandler *handlers.InventoryHandler, cfg *config.Config) *Server {
+// NewServer wires up the chi router. amqpConn may be nil when the outbox
+// publisher was never connected (e.g. RABBITMQ_URL is unset); the
+// RabbitMQ checker simply reports failing in that case.
+func NewServer(inventoryHandler *handlers.InventoryHandler, cfg *config.Config, db *pgxpool.Pool, amqpConn *amqp.Connection) *Server {
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register(health.DBChecker(db), true, 0)
+	healthRegistry.Register(health.AMQPChecker(amqpConn), false, 0)
+
 	return &Server{
 		router:  chi.NewRouter(),
 		cfg:     cfg,
 		handler: inventoryHandler,
+		health:  healthRegistry,
+		db:      db,
 	}
 }
 
@@ -37,17 +55,29 @@ func (s *Server) registerRoutes() {
 	// Expose metrics endpoint for Prometheus scraping
 	s.router.Handle("/metrics", promhttp.Handler())
 
-	// Health check endpoint
-	s.router.Get("/health", s.handler.Health)
+	// Health check endpoints: /health/live for liveness, /health/ready for
+	// readiness (503s while a critical dependency is failing), /health for
+	// the full aggregate report.
+	s.router.Get("/health/live", s.health.Live)
+	s.router.Get("/health/ready", s.health.Ready)
+	s.router.Get("/health", s.health.Health)
 
-	// Product endpoints
-	s.router.Post("/products", s.handler.CreateProduct)
+	// Product endpoints. Mutations require an authenticated admin; reads stay
+	// open since the storefront browses the catalog without logging in.
 	s.router.Get("/products", s.handler.GetAllProducts)
 	s.router.Get("/products/{id}", s.handler.GetProduct)
-	s.router.Put("/products/{id}", s.handler.UpdateProduct)
-	s.router.Delete("/products/{id}", s.handler.DeleteProduct)
+
+	s.router.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth, middleware.RequireRole("admin"))
+		r.Post("/products", s.handler.CreateProduct)
+		r.Put("/products/{id}", s.handler.UpdateProduct)
+		r.Delete("/products/{id}", s.handler.DeleteProduct)
+	})
+
 	s.router.Post("/products/{id}/reserve", s.handler.ReserveProduct)
 	s.router.Post("/products/{id}/release", s.handler.ReleaseProduct)
+	s.router.Post("/inventory/reserve-batch", s.handler.ReserveBatch)
+	s.router.Post("/admin/reseed", s.handler.Reseed)
 }
 
 func (s *Server) Start() {
@@ -56,9 +86,35 @@ func (s *Server) Start() {
 	if s.cfg.Port == "" {
 		slog.Error("Port not specified in configuration")
 	}
+
+	if s.cfg.SeedPath != "" {
+		count, err := seeds.Load(context.Background(), s.db, s.cfg.SeedPath)
+		if err != nil {
+			slog.Error("Failed to load seed data", "seed_path", s.cfg.SeedPath, "error", err)
+		} else {
+			slog.Info("Seed data loaded", "seed_path", s.cfg.SeedPath, "products", count)
+		}
+	}
+
+	if s.cfg.GRPCPort != "" {
+		go s.startGRPC()
+	}
+
 	slog.Info("Inventory Service is starting.", "port", addr, "level", slog.LevelInfo)
 	err := http.ListenAndServe(":"+addr, s.router)
 	if err != nil {
 		log.Fatal("ListenAndServe error: ", err)
 	}
 }
+
+// startGRPC runs the InventoryService gRPC server on its own port alongside
+// the chi HTTP server, sharing the same database pool and business logic.
+func (s *Server) startGRPC() {
+	grpcServer := grpc.NewServer(grpcserver.ServerOptions()...)
+	grpcserver.New(s.db).Register(grpcServer)
+
+	slog.Info("Inventory Service gRPC server is starting.", "port", s.cfg.GRPCPort)
+	if err := grpcserver.Serve(context.Background(), grpcServer, ":"+s.cfg.GRPCPort); err != nil {
+		slog.Error("gRPC ListenAndServe error", "error", err)
+	}
+}
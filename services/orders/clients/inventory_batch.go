@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BatchItem is one line of a bulk reservation request.
+type BatchItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// batchReserveRequest/batchReserveResponse/batchReservationFailure mirror the
+// wire types the inventory service's ReserveBatch handler encodes.
+type batchReserveRequest struct {
+	Items []BatchItem `json:"items"`
+}
+
+type batchReserveResponse struct {
+	Products []ReservationResponse `json:"products"`
+}
+
+type batchReservationFailure struct {
+	Error    string            `json:"error"`
+	Failures map[string]string `json:"failures"`
+}
+
+// BatchReservationError reports, per product ID, why a line of a bulk
+// reservation was rejected. The whole batch is rolled back on the inventory
+// side, so callers can choose to reject the order outright or retry with the
+// surviving lines rather than reconciling a partially-applied reservation.
+type BatchReservationError struct {
+	Failures map[string]string
+}
+
+func (e *BatchReservationError) Error() string {
+	reasons := make([]string, 0, len(e.Failures))
+	for productID, reason := range e.Failures {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", productID, reason))
+	}
+	return fmt.Sprintf("batch reservation failed (%s)", strings.Join(reasons, "; "))
+}
+
+// ReserveBatch reserves multiple products atomically. Unlike ReserveProduct,
+// it is not retried: a BatchReservationError reflects a business-level
+// rejection (insufficient stock, unknown product), not a transient failure,
+// so retrying it would just fail the same way.
+func (c *InventoryClient) ReserveBatch(ctx context.Context, items []BatchItem) ([]ReservationResponse, error) {
+	tracer := otel.Tracer("orders-service")
+	ctx, span := tracer.Start(ctx, "InventoryClient.ReserveBatch")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("batch.size", len(items)),
+		attribute.String("inventory.base_url", c.baseURL),
+	)
+
+	slog.Info("Attempting batch reservation", "batch_size", len(items))
+
+	var responses []ReservationResponse
+	var businessErr *BatchReservationError
+
+	// Execute with circuit breaker protection. A BatchReservationError is a
+	// business-level rejection by the inventory service, not a sign it's
+	// unhealthy, so we capture it here and report nil to Execute instead of
+	// letting it count toward the breaker's failure threshold - the same
+	// treatment ReserveProduct gives ErrInsufficientStock/ErrProductNotFound.
+	err := c.circuitBreaker.Execute(ctx, func() error {
+		var innerErr error
+		responses, innerErr = c.transport.reserveBatch(ctx, items)
+		var batchErr *BatchReservationError
+		if errors.As(innerErr, &batchErr) {
+			businessErr = batchErr
+			return nil
+		}
+		return innerErr
+	})
+
+	if businessErr != nil {
+		span.RecordError(businessErr)
+		span.SetStatus(codes.Error, businessErr.Error())
+		return nil, businessErr
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "batch reserved successfully")
+	return responses, nil
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"time"
 
+	invErrors "github.com/rafidoth/orders-service/errors"
 	"github.com/rafidoth/orders-service/resilience"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -41,28 +42,75 @@ type reserveRequest struct {
 	Quantity int `json:"quantity"`
 }
 
-// InventoryClient handles HTTP calls to the inventory service with resilience
+// transport performs the actual reserve/release calls against the inventory
+// service, independent of the retry/circuit-breaker policy layered on top of
+// it in InventoryClient. httpTransport and grpcTransport are the two
+// implementations; NewInventoryClient and NewInventoryGRPCClient pick one.
+type transport interface {
+	reserve(ctx context.Context, productID string, quantity int) (*ReservationResponse, error)
+	release(ctx context.Context, productID string, quantity int) error
+	reserveBatch(ctx context.Context, items []BatchItem) ([]ReservationResponse, error)
+}
+
+// InventoryClient handles calls to the inventory service with resilience
+// (circuit breaker + retry) layered over a pluggable transport (HTTP or gRPC).
 type InventoryClient struct {
 	baseURL        string
-	client         *http.Client
+	transport      transport
 	circuitBreaker *resilience.CircuitBreaker
-	maxRetries     int
-	retryDelay     time.Duration
+	newBackoff     func() resilience.Backoff
 }
 
-// NewInventoryClient creates a new inventory client with circuit breaker protection
-func NewInventoryClient(baseURL string, requestTimeout time.Duration, cbMaxFailures int, cbTimeout time.Duration) *InventoryClient {
-	return &InventoryClient{
-		baseURL: baseURL,
-		client: &http.Client{
-			Timeout: requestTimeout,
-		},
-		circuitBreaker: resilience.NewCircuitBreaker("inventory-service", cbMaxFailures, cbTimeout),
-		maxRetries:     3,
-		retryDelay:     100 * time.Millisecond,
+// InventoryClientOption customizes an InventoryClient beyond its required
+// constructor arguments.
+type InventoryClientOption func(*InventoryClient)
+
+// WithBackoffFactory overrides the retry backoff policy. newBackoff is
+// called once per retried operation so each call gets its own independent
+// backoff state - tests can inject a factory that returns a
+// zero-jitter, zero-delay Backoff for deterministic, fast-running retries.
+func WithBackoffFactory(newBackoff func() resilience.Backoff) InventoryClientOption {
+	return func(c *InventoryClient) {
+		c.newBackoff = newBackoff
 	}
 }
 
+// NewInventoryClient creates a new inventory client that talks HTTP, with
+// circuit breaker protection.
+func NewInventoryClient(baseURL string, requestTimeout time.Duration, cbMaxFailures int, cbTimeout time.Duration, opts ...InventoryClientOption) *InventoryClient {
+	c := &InventoryClient{
+		baseURL:        baseURL,
+		transport:      newHTTPTransport(baseURL, requestTimeout),
+		circuitBreaker: resilience.NewCircuitBreaker("inventory-service", cbMaxFailures, cbTimeout, resilience.WithMetrics(resilience.NewPrometheusMetrics())),
+		newBackoff:     func() resilience.Backoff { return resilience.NewExponentialBackoff() },
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewInventoryGRPCClient creates a new inventory client that talks gRPC
+// instead of HTTP, with the same retry/circuit-breaker policy. addr is a
+// "host:port" dial target for the inventory service's gRPC listener.
+func NewInventoryGRPCClient(addr string, requestTimeout time.Duration, cbMaxFailures int, cbTimeout time.Duration, opts ...InventoryClientOption) (*InventoryClient, error) {
+	t, err := newGRPCTransport(addr, requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &InventoryClient{
+		baseURL:        addr,
+		transport:      t,
+		circuitBreaker: resilience.NewCircuitBreaker("inventory-service", cbMaxFailures, cbTimeout, resilience.WithMetrics(resilience.NewPrometheusMetrics())),
+		newBackoff:     func() resilience.Backoff { return resilience.NewExponentialBackoff() },
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
 // ReserveProduct reserves a quantity of a product in the inventory service
 func (c *InventoryClient) ReserveProduct(ctx context.Context, productID string, quantity int) (*ReservationResponse, error) {
 	tracer := otel.Tracer("orders-service")
@@ -80,16 +128,30 @@ func (c *InventoryClient) ReserveProduct(ctx context.Context, productID string,
 		"quantity", quantity,
 	)
 
-	var lastErr error
 	var response *ReservationResponse
+	var businessErr error
 
-	// Execute with circuit breaker protection
+	// Execute with circuit breaker protection. Insufficient stock / product
+	// not found are rejections by the inventory service's own business
+	// logic, not a sign the service is unhealthy, so we capture them here
+	// and report nil to Execute instead of letting them count toward the
+	// breaker's failure threshold.
 	err := c.circuitBreaker.Execute(ctx, func() error {
 		var innerErr error
 		response, innerErr = c.doReserveWithRetry(ctx, productID, quantity)
+		if errors.Is(innerErr, ErrInsufficientStock) || errors.Is(innerErr, ErrProductNotFound) {
+			businessErr = innerErr
+			return nil
+		}
 		return innerErr
 	})
 
+	if businessErr != nil {
+		span.RecordError(businessErr)
+		span.SetStatus(codes.Error, businessErr.Error())
+		return nil, businessErr
+	}
+
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -103,28 +165,30 @@ func (c *InventoryClient) ReserveProduct(ctx context.Context, productID string,
 		"available_quantity", response.AvailableQuantity,
 	)
 
-	if lastErr != nil {
-		return nil, lastErr
-	}
-
 	return response, nil
 }
 
-// doReserveWithRetry performs the reserve operation with exponential backoff retry
+// doReserveWithRetry performs the reserve operation, retrying with
+// c.newBackoff's policy until it succeeds, hits a non-retryable error, or
+// the backoff itself gives up (elapsed time exceeded or NextBackOff
+// returns resilience.Stop).
 func (c *InventoryClient) doReserveWithRetry(ctx context.Context, productID string, quantity int) (*ReservationResponse, error) {
 	tracer := otel.Tracer("orders-service")
+	backoff := c.newBackoff()
 
 	var lastErr error
-	delay := c.retryDelay
+	var lastDelay time.Duration
+	attempt := 0
 
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+	for {
+		attempt++
 		ctx, span := tracer.Start(ctx, "InventoryClient.ReserveProduct.Attempt")
 		span.SetAttributes(
-			attribute.Int("attempt", attempt+1),
-			attribute.Int("max_retries", c.maxRetries+1),
+			attribute.Int("attempt", attempt),
+			attribute.Int64("retry.delay_ms", lastDelay.Milliseconds()),
 		)
 
-		response, err := c.doReserve(ctx, productID, quantity)
+		response, err := c.transport.reserve(ctx, productID, quantity)
 		if err == nil {
 			span.SetStatus(codes.Ok, "success")
 			span.End()
@@ -146,74 +210,25 @@ func (c *InventoryClient) doReserveWithRetry(ctx context.Context, productID stri
 			return nil, ctx.Err()
 		}
 
-		// Check if we've exhausted retries
-		if attempt < c.maxRetries {
-			slog.Info("Retrying reserve operation after failure",
-				"attempt", attempt+1,
-				"max_retries", c.maxRetries+1,
-				"delay", delay,
-				"error", err,
-			)
-			select {
-			case <-time.After(delay):
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			}
-			delay *= 2 // Exponential backoff
+		delay := backoff.NextBackOff()
+		if delay == resilience.Stop {
+			break
 		}
-	}
-
-	return nil, fmt.Errorf("reserve operation failed after %d attempts: %w", c.maxRetries+1, lastErr)
-}
-
-// doReserve performs a single reserve HTTP request
-func (c *InventoryClient) doReserve(ctx context.Context, productID string, quantity int) (*ReservationResponse, error) {
-	url := fmt.Sprintf("%s/products/%s/reserve", c.baseURL, productID)
-
-	reqBody := reserveRequest{Quantity: quantity}
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Propagate trace context
-	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+		lastDelay = delay
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInventoryService, err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var response ReservationResponse
-		if err := json.Unmarshal(body, &response); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-		return &response, nil
-	case http.StatusBadRequest:
-		// Could be insufficient stock or product not found
-		if bytes.Contains(body, []byte("Insufficient")) {
-			return nil, ErrInsufficientStock
+		slog.Info("Retrying reserve operation after failure",
+			"attempt", attempt,
+			"delay", delay,
+			"error", err,
+		)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
-		return nil, ErrProductNotFound
-	case http.StatusNotFound:
-		return nil, ErrProductNotFound
-	default:
-		return nil, fmt.Errorf("%w: status %d, body: %s", ErrInventoryService, resp.StatusCode, string(body))
 	}
+
+	return nil, fmt.Errorf("reserve operation failed after %d attempts: %w", attempt, lastErr)
 }
 
 // ReleaseProduct releases a reserved quantity of a product in the inventory service
@@ -251,47 +266,181 @@ func (c *InventoryClient) ReleaseProduct(ctx context.Context, productID string,
 	return nil
 }
 
-// doReleaseWithRetry performs the release operation with exponential backoff retry
+// doReleaseWithRetry performs the release operation, retrying with
+// c.newBackoff's policy the same way doReserveWithRetry does.
 func (c *InventoryClient) doReleaseWithRetry(ctx context.Context, productID string, quantity int) error {
+	tracer := otel.Tracer("orders-service")
+	backoff := c.newBackoff()
+
 	var lastErr error
-	delay := c.retryDelay
+	var lastDelay time.Duration
+	attempt := 0
 
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		err := c.doRelease(ctx, productID, quantity)
+	for {
+		attempt++
+		ctx, span := tracer.Start(ctx, "InventoryClient.ReleaseProduct.Attempt")
+		span.SetAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.Int64("retry.delay_ms", lastDelay.Milliseconds()),
+		)
+
+		err := c.transport.release(ctx, productID, quantity)
 		if err == nil {
+			span.SetStatus(codes.Ok, "success")
+			span.End()
 			return nil
 		}
 
 		lastErr = err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
 
 		// Don't retry on context cancellation
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
 
-		// Check if we've exhausted retries
-		if attempt < c.maxRetries {
-			slog.Info("Retrying release operation after failure",
-				"attempt", attempt+1,
-				"max_retries", c.maxRetries+1,
-				"delay", delay,
-				"error", err,
-			)
-			select {
-			case <-time.After(delay):
-			case <-ctx.Done():
-				return ctx.Err()
+		delay := backoff.NextBackOff()
+		if delay == resilience.Stop {
+			break
+		}
+		lastDelay = delay
+
+		slog.Info("Retrying release operation after failure",
+			"attempt", attempt,
+			"delay", delay,
+			"error", err,
+		)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return fmt.Errorf("release operation failed after %d attempts: %w", attempt, lastErr)
+}
+
+// CircuitState returns the current state of the circuit breaker
+func (c *InventoryClient) CircuitState() resilience.State {
+	return c.circuitBreaker.State()
+}
+
+// httpTransport is the default transport, calling the inventory service's
+// chi HTTP API.
+type httpTransport struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newHTTPTransport(baseURL string, requestTimeout time.Duration) *httpTransport {
+	return &httpTransport{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (t *httpTransport) reserve(ctx context.Context, productID string, quantity int) (*ReservationResponse, error) {
+	url := fmt.Sprintf("%s/products/%s/reserve", t.baseURL, productID)
+
+	reqBody := reserveRequest{Quantity: quantity}
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Propagate trace context
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInventoryService, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var response ReservationResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return &response, nil
+	case http.StatusConflict, http.StatusNotFound:
+		// A business-level rejection: the inventory service returns a
+		// structured envelope instead of a plain-text body so we don't have
+		// to guess the reason from wording.
+		var env invErrors.Envelope
+		if err := json.Unmarshal(body, &env); err != nil || env.Code == "" {
+			if resp.StatusCode == http.StatusNotFound {
+				return nil, ErrProductNotFound
 			}
-			delay *= 2 // Exponential backoff
+			return nil, ErrInsufficientStock
 		}
+		return nil, newInventoryError(env)
+	default:
+		return nil, fmt.Errorf("%w: status %d, body: %s", ErrInventoryService, resp.StatusCode, string(body))
+	}
+}
+
+func (t *httpTransport) reserveBatch(ctx context.Context, items []BatchItem) ([]ReservationResponse, error) {
+	url := fmt.Sprintf("%s/inventory/reserve-batch", t.baseURL)
+
+	bodyBytes, err := json.Marshal(batchReserveRequest{Items: items})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInventoryService, err)
 	}
+	defer resp.Body.Close()
 
-	return fmt.Errorf("release operation failed after %d attempts: %w", c.maxRetries+1, lastErr)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var batchResp batchReserveResponse
+		if err := json.Unmarshal(body, &batchResp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return batchResp.Products, nil
+	case http.StatusConflict:
+		var failure batchReservationFailure
+		if err := json.Unmarshal(body, &failure); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal batch failure: %w", err)
+		}
+		return nil, &BatchReservationError{Failures: failure.Failures}
+	default:
+		return nil, fmt.Errorf("%w: status %d, body: %s", ErrInventoryService, resp.StatusCode, string(body))
+	}
 }
 
-// doRelease performs a single release HTTP request
-func (c *InventoryClient) doRelease(ctx context.Context, productID string, quantity int) error {
-	url := fmt.Sprintf("%s/products/%s/release", c.baseURL, productID)
+func (t *httpTransport) release(ctx context.Context, productID string, quantity int) error {
+	url := fmt.Sprintf("%s/products/%s/release", t.baseURL, productID)
 
 	reqBody := reserveRequest{Quantity: quantity}
 	bodyBytes, err := json.Marshal(reqBody)
@@ -308,7 +457,7 @@ func (c *InventoryClient) doRelease(ctx context.Context, productID string, quant
 	// Propagate trace context
 	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
-	resp, err := c.client.Do(req)
+	resp, err := t.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrInventoryService, err)
 	}
@@ -321,8 +470,3 @@ func (c *InventoryClient) doRelease(ctx context.Context, productID string, quant
 	body, _ := io.ReadAll(resp.Body)
 	return fmt.Errorf("%w: status %d, body: %s", ErrInventoryService, resp.StatusCode, string(body))
 }
-
-// CircuitState returns the current state of the circuit breaker
-func (c *InventoryClient) CircuitState() resilience.State {
-	return c.circuitBreaker.State()
-}
@@ -0,0 +1,46 @@
+package clients
+
+import (
+	invErrors "github.com/rafidoth/orders-service/errors"
+)
+
+// InventoryError wraps a structured business-level rejection from the
+// inventory service (insufficient stock, unknown product), carrying the
+// available/requested counts that a plain sentinel error can't. Callers
+// that only care about the broad category can still use
+// errors.Is(err, ErrInsufficientStock) / errors.Is(err, ErrProductNotFound)
+// via Unwrap.
+type InventoryError struct {
+	Code      string
+	Message   string
+	ProductID string
+	Available int
+	Requested int
+}
+
+func (e *InventoryError) Error() string {
+	return e.Message
+}
+
+func (e *InventoryError) Unwrap() error {
+	switch e.Code {
+	case invErrors.CodeInsufficientStock:
+		return ErrInsufficientStock
+	case invErrors.CodeProductNotFound:
+		return ErrProductNotFound
+	default:
+		return ErrInventoryService
+	}
+}
+
+// newInventoryError builds an InventoryError from the envelope the
+// inventory service responded with.
+func newInventoryError(env invErrors.Envelope) *InventoryError {
+	return &InventoryError{
+		Code:      env.Code,
+		Message:   env.Message,
+		ProductID: env.Details.ProductID,
+		Available: env.Details.Available,
+		Requested: env.Details.Requested,
+	}
+}
@@ -0,0 +1,87 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rafidoth/train-ticket-booking-microservice/inventory/proto/inventorypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// grpcTransport talks to the inventory service's InventoryService gRPC API
+// instead of its chi HTTP API. It translates gRPC status codes back to the
+// same sentinel errors httpTransport produces, so the retry/circuit-breaker
+// logic in InventoryClient doesn't need to know which transport is active.
+type grpcTransport struct {
+	conn   *grpc.ClientConn
+	client inventorypb.InventoryServiceClient
+}
+
+func newGRPCTransport(addr string, requestTimeout time.Duration) (*grpcTransport, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial inventory service: %w", err)
+	}
+
+	return &grpcTransport{
+		conn:   conn,
+		client: inventorypb.NewInventoryServiceClient(conn),
+	}, nil
+}
+
+func (t *grpcTransport) reserve(ctx context.Context, productID string, quantity int) (*ReservationResponse, error) {
+	p, err := t.client.ReserveProduct(ctx, &inventorypb.ReserveProductRequest{
+		ProductId: productID,
+		Quantity:  int32(quantity),
+	})
+	if err != nil {
+		return nil, translateGRPCErr(err)
+	}
+
+	return &ReservationResponse{
+		ID:                p.GetId(),
+		Title:             p.GetTitle(),
+		Price:             p.GetPrice(),
+		AvailableQuantity: int(p.GetAvailableQuantity()),
+		Reserved:          int(p.GetReserved()),
+		CreatedAt:         p.GetCreatedAt().AsTime(),
+		UpdatedAt:         p.GetUpdatedAt().AsTime(),
+	}, nil
+}
+
+// reserveBatch has no gRPC equivalent yet: InventoryService's proto contract
+// doesn't define a batch RPC. Callers configured for the gRPC transport fall
+// back to ErrInventoryService rather than silently reserving items one at a
+// time, which would lose the atomicity batch reservation is meant to provide.
+func (t *grpcTransport) reserveBatch(ctx context.Context, items []BatchItem) ([]ReservationResponse, error) {
+	return nil, fmt.Errorf("%w: batch reservation is not supported over the gRPC transport", ErrInventoryService)
+}
+
+func (t *grpcTransport) release(ctx context.Context, productID string, quantity int) error {
+	_, err := t.client.ReleaseProduct(ctx, &inventorypb.ReleaseProductRequest{
+		ProductId: productID,
+		Quantity:  int32(quantity),
+	})
+	if err != nil {
+		return translateGRPCErr(err)
+	}
+	return nil
+}
+
+// translateGRPCErr maps the status codes the inventory gRPC server actually
+// returns (see services/inventory/grpcserver/server.go) onto the sentinel
+// errors callers of InventoryClient already branch on.
+func translateGRPCErr(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return ErrProductNotFound
+	case codes.FailedPrecondition:
+		return ErrInsufficientStock
+	default:
+		return fmt.Errorf("%w: %v", ErrInventoryService, err)
+	}
+}
@@ -0,0 +1,186 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rafidoth/orders-service/resilience"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Sentinel errors for the payment client
+var (
+	ErrPaymentNotFound = errors.New("payment not found")
+	ErrPaymentService  = errors.New("payment service error")
+)
+
+// PaymentStatus is the subset of the payment service's Payment resource the
+// orders service needs to answer "how did this order's payment go".
+type PaymentStatus struct {
+	ID            string  `json:"id"`
+	OrderID       string  `json:"order_id"`
+	Status        string  `json:"status"`
+	FailureReason string  `json:"failure_reason,omitempty"`
+	Amount        float64 `json:"amount"`
+}
+
+// PaymentClient looks up payment status from the payment service, with
+// circuit breaker and jittered retry protection so a slow/unhealthy payment
+// service can't back up order lookups.
+type PaymentClient struct {
+	baseURL string
+	client  *resilience.Client
+}
+
+// NewPaymentClient creates a payment client with its own named circuit
+// breaker, independent of the inventory client's.
+func NewPaymentClient(baseURL string, requestTimeout time.Duration, cbMaxFailures int, cbTimeout time.Duration) *PaymentClient {
+	return &PaymentClient{
+		baseURL: baseURL,
+		client:  resilience.NewClient("payment-service", requestTimeout, cbMaxFailures, cbTimeout),
+	}
+}
+
+// GetPaymentByOrderID fetches the most recent payment recorded for an order.
+func (c *PaymentClient) GetPaymentByOrderID(ctx context.Context, orderID string) (*PaymentStatus, error) {
+	url := fmt.Sprintf("%s/payments/order/%s", c.baseURL, orderID)
+
+	slog.Info("Fetching payment status", "order_id", orderID)
+
+	resp, err := c.client.Do(ctx, "PaymentClient.GetPaymentByOrderID", func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+		return req, nil
+	}, resilience.RetryOn5xx)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentService, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var status PaymentStatus
+		if err := json.Unmarshal(body, &status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return &status, nil
+	case http.StatusNotFound:
+		return nil, ErrPaymentNotFound
+	default:
+		return nil, fmt.Errorf("%w: status %d, body: %s", ErrPaymentService, resp.StatusCode, string(body))
+	}
+}
+
+// ErrPaymentDeclined is returned when the payment service processed the
+// charge but declined it (e.g. the test decline card), as opposed to the
+// payment service itself being unavailable (ErrPaymentService).
+var ErrPaymentDeclined = errors.New("payment declined")
+
+// ChargeRequest carries the fields ChargePayment forwards to the payment
+// service's ProcessPayment endpoint.
+type ChargeRequest struct {
+	OrderID        string  `json:"order_id"`
+	UserID         string  `json:"user_id"`
+	Amount         float64 `json:"amount"`
+	CardNumber     string  `json:"card_number"`
+	ExpiryMonth    string  `json:"expiry_month"`
+	ExpiryYear     string  `json:"expiry_year"`
+	CVV            string  `json:"cvv"`
+	CardholderName string  `json:"cardholder_name"`
+	IdempotencyKey string  `json:"idempotency_key,omitempty"`
+}
+
+// ChargePayment charges a card for an order via the payment service. A
+// declined card (payment service responds 402 with Success: false) surfaces
+// as ErrPaymentDeclined rather than a transport-level error, so callers can
+// tell "the charge failed" apart from "the payment service is unreachable".
+func (c *PaymentClient) ChargePayment(ctx context.Context, req ChargeRequest) (*PaymentStatus, error) {
+	url := fmt.Sprintf("%s/payments", c.baseURL)
+
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal charge request: %w", err)
+	}
+
+	resp, err := c.client.Do(ctx, "PaymentClient.ChargePayment", func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		if req.IdempotencyKey != "" {
+			httpReq.Header.Set("Idempotency-Key", req.IdempotencyKey)
+		}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+		return httpReq, nil
+	}, resilience.RetryOn5xx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentService, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var result struct {
+		Success bool           `json:"success"`
+		Message string         `json:"message"`
+		Payment *PaymentStatus `json:"payment"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("%w: status %d, body: %s", ErrPaymentService, resp.StatusCode, string(body))
+	}
+
+	if !result.Success {
+		if result.Payment != nil {
+			return result.Payment, fmt.Errorf("%w: %s", ErrPaymentDeclined, result.Payment.FailureReason)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPaymentDeclined, result.Message)
+	}
+	return result.Payment, nil
+}
+
+// RefundPayment reverses a completed charge, the compensation for
+// ChargePayment. It's safe to retry: refunding an already-refunded payment
+// is a no-op on the payment service side.
+func (c *PaymentClient) RefundPayment(ctx context.Context, paymentID string) error {
+	url := fmt.Sprintf("%s/payments/%s/refund", c.baseURL, paymentID)
+
+	resp, err := c.client.Do(ctx, "PaymentClient.RefundPayment", func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+		return httpReq, nil
+	}, resilience.RetryOn5xx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPaymentService, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: status %d, body: %s", ErrPaymentService, resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -10,7 +10,13 @@ import (
 type Config struct {
 	DB                  PostgresConfig
 	Port                string
+	GRPCPort            string
 	InventoryServiceURL string
+	InventoryTransport  string // "http" (default) or "grpc"
+	InventoryGRPCAddr   string // dial target when InventoryTransport is "grpc"
+	PaymentServiceURL   string
+	RabbitMqURL         string // AMQP URL the outbox publisher connects to; publishing is disabled when empty
+	SeedPath            string // when set, demo orders are (re)seeded from this JSON file on startup
 	Resilience          ResilienceConfig
 }
 
@@ -37,12 +43,28 @@ func LoadConfig() (*Config, error) {
 		inventoryServiceURL = "http://inventory-service:5002"
 	}
 
+	inventoryTransport := os.Getenv("INVENTORY_TRANSPORT")
+	if inventoryTransport == "" {
+		inventoryTransport = "http"
+	}
+
+	paymentServiceURL := os.Getenv("PAYMENT_SERVICE_URL")
+	if paymentServiceURL == "" {
+		paymentServiceURL = "http://payment-service:5003"
+	}
+
 	return &Config{
-		Port: os.Getenv("PORT"),
+		Port:     os.Getenv("PORT"),
+		GRPCPort: os.Getenv("GRPC_PORT"),
 		DB: PostgresConfig{
 			DBString: os.Getenv("DBSTRING"),
 		},
 		InventoryServiceURL: inventoryServiceURL,
+		InventoryTransport:  inventoryTransport,
+		InventoryGRPCAddr:   os.Getenv("INVENTORY_GRPC_ADDR"),
+		PaymentServiceURL:   paymentServiceURL,
+		RabbitMqURL:         os.Getenv("RABBITMQ_URL"),
+		SeedPath:            os.Getenv("SEED_PATH"),
 		Resilience: ResilienceConfig{
 			TimeoutSeconds:        timeoutSeconds,
 			CircuitMaxFailures:    circuitMaxFailures,
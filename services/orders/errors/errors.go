@@ -0,0 +1,25 @@
+// Package errors mirrors the structured error envelope the inventory
+// service returns for business-level rejections (insufficient stock, an
+// unknown product), so the orders client can parse Code instead of
+// pattern-matching the inventory service's response body.
+package errors
+
+// Codes the inventory service returns in Envelope.Code.
+const (
+	CodeInsufficientStock = "INSUFFICIENT_STOCK"
+	CodeProductNotFound   = "PRODUCT_NOT_FOUND"
+)
+
+// Details carries the extra context a Code needs.
+type Details struct {
+	ProductID string `json:"product_id,omitempty"`
+	Available int    `json:"available,omitempty"`
+	Requested int    `json:"requested,omitempty"`
+}
+
+// Envelope is the JSON body a business-level rejection is returned with.
+type Envelope struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Details Details `json:"details,omitempty"`
+}
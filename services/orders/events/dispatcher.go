@@ -0,0 +1,245 @@
+package events
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafidoth/orders-service/clients"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	batchSize        = 10
+	maxAttempts      = 5
+	baseRetryDelay   = 2 * time.Second
+	defaultPollEvery = 3 * time.Second
+)
+
+// Dispatcher polls the order_events outbox and replays pending reservation
+// intents against the inventory service, compensating the order on terminal
+// failure (the saga pattern referenced by the checkout flow docs).
+type Dispatcher struct {
+	db              *pgxpool.Pool
+	store           *Store
+	inventoryClient *clients.InventoryClient
+	pollInterval    time.Duration
+}
+
+// NewDispatcher builds a Dispatcher. db is used to transition order status;
+// store owns the order_events rows dispatched from.
+func NewDispatcher(db *pgxpool.Pool, store *Store, inventoryClient *clients.InventoryClient) *Dispatcher {
+	return &Dispatcher{
+		db:              db,
+		store:           store,
+		inventoryClient: inventoryClient,
+		pollInterval:    defaultPollEvery,
+	}
+}
+
+// Start runs the poll loop until ctx is cancelled. Intended to be launched
+// in its own goroutine from main().
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	slog.Info("order events dispatcher starting", "poll_interval", d.pollInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("order events dispatcher stopping")
+			return
+		case <-ticker.C:
+			d.runOnce(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) runOnce(ctx context.Context) {
+	tx, pending, err := d.store.claimPending(ctx, batchSize)
+	if err != nil {
+		slog.Error("failed to claim pending order events", "error", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	for _, event := range pending {
+		d.process(ctx, tx, event)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("failed to commit dispatched order events", "error", err)
+	}
+}
+
+func (d *Dispatcher) process(ctx context.Context, tx pgx.Tx, event Event) {
+	tracer := otel.Tracer("orders-service")
+
+	var opts []trace.SpanStartOption
+	if link, ok := linkFromEvent(event); ok {
+		opts = append(opts, trace.WithLinks(link))
+	}
+
+	ctx, span := tracer.Start(ctx, "OrderEventsDispatcher.Process", opts...)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("order_event.id", event.ID),
+		attribute.String("order.id", event.OrderID),
+		attribute.String("order_event.type", string(event.EventType)),
+		attribute.Int("order_event.attempts", event.Attempts),
+	)
+
+	switch event.EventType {
+	case TypeReservationPending:
+		d.processReservation(ctx, tx, event, span)
+	default:
+		span.SetStatus(codes.Error, "unknown event type")
+		_ = d.store.markFailed(ctx, tx, event.ID)
+	}
+}
+
+func (d *Dispatcher) processReservation(ctx context.Context, tx pgx.Tx, event Event, span trace.Span) {
+	var payload ReservationPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid payload")
+		_ = d.store.markFailed(ctx, tx, event.ID)
+		return
+	}
+
+	_, err := d.inventoryClient.ReserveProduct(ctx, payload.ProductID, payload.Quantity)
+	if err == nil {
+		span.SetStatus(codes.Ok, "reservation dispatched")
+		if err := d.store.markDispatched(ctx, tx, event.ID); err != nil {
+			slog.Error("failed to mark order event dispatched", "event_id", event.ID, "error", err)
+		}
+		if err := d.confirmOrder(ctx, tx, event); err != nil {
+			slog.Error("failed to confirm order after async reservation", "order_id", event.OrderID, "error", err)
+		}
+		return
+	}
+
+	span.RecordError(err)
+
+	terminal := errors.Is(err, clients.ErrInsufficientStock) || errors.Is(err, clients.ErrProductNotFound)
+	if terminal || event.Attempts+1 >= maxAttempts {
+		span.SetStatus(codes.Error, "reservation permanently failed, compensating")
+		d.compensate(ctx, tx, event, err)
+		return
+	}
+
+	span.SetStatus(codes.Error, "reservation retry scheduled")
+	delay := baseRetryDelay << event.Attempts // exponential backoff
+	if err := d.store.reschedule(ctx, tx, event.ID, time.Now().Add(delay)); err != nil {
+		slog.Error("failed to reschedule order event", "event_id", event.ID, "error", err)
+	}
+}
+
+// compensate cancels the order and publishes the ORDER_CANCELLED compensating
+// event once async reservation is no longer recoverable. A degraded multi-item
+// order enqueues one RESERVATION_PENDING event per item, so a later item's
+// permanent failure can arrive after earlier items already reserved stock and
+// confirmed the order; the UPDATE below is conditioned on the order not
+// already being CANCELLED so it only runs once, and on that first run it
+// releases every sibling reservation that already succeeded rather than
+// leaving that stock held against a cancelled order.
+func (d *Dispatcher) compensate(ctx context.Context, tx pgx.Tx, event Event, cause error) {
+	if err := d.store.markFailed(ctx, tx, event.ID); err != nil {
+		slog.Error("failed to mark order event failed", "event_id", event.ID, "error", err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE orders SET status = 'CANCELLED', updated_at = NOW() WHERE id = $1 AND status != 'CANCELLED'`,
+		event.OrderID,
+	)
+	if err != nil {
+		slog.Error("failed to cancel order after reservation failure", "order_id", event.OrderID, "error", err)
+		return
+	}
+
+	if err := d.store.recordCancellation(ctx, tx, event.OrderID, cause.Error()); err != nil {
+		slog.Error("failed to record order cancellation event", "order_id", event.OrderID, "error", err)
+	}
+
+	slog.Warn("order cancelled by saga compensation", "order_id", event.OrderID, "cause", cause)
+
+	if tag.RowsAffected() == 0 {
+		// Another event for this order already cancelled it (and released its
+		// siblings' reservations) in an earlier run; nothing left to release.
+		return
+	}
+
+	siblings, err := d.store.dispatchedSiblingReservations(ctx, tx, event.OrderID, event.ID)
+	if err != nil {
+		slog.Error("failed to list reservations to release for cancelled order", "order_id", event.OrderID, "error", err)
+		return
+	}
+	for _, reservation := range siblings {
+		if err := d.inventoryClient.ReleaseProduct(ctx, reservation.ProductID, reservation.Quantity); err != nil {
+			slog.Error("failed to release sibling reservation for cancelled order",
+				"order_id", event.OrderID, "product_id", reservation.ProductID, "error", err)
+		}
+	}
+}
+
+// confirmOrder flips orderID to CONFIRMED once its reservation succeeds, but
+// only once every other RESERVATION_PENDING event for the same order has also
+// cleared - a degraded multi-item order enqueues one event per item, and
+// confirming on the first success alone would let a later item's failure
+// cancel an order already reported as confirmed to the customer.
+func (d *Dispatcher) confirmOrder(ctx context.Context, tx pgx.Tx, event Event) error {
+	pending, err := d.store.hasPendingSiblings(ctx, tx, event.OrderID, event.ID)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE orders SET status = 'CONFIRMED', updated_at = NOW() WHERE id = $1 AND status = 'PENDING_INVENTORY'`,
+		event.OrderID,
+	)
+	return err
+}
+
+// linkFromEvent rebuilds the trace.Link to the CreateOrder span that
+// originally recorded this event, so the dispatcher span shows up as
+// causally related rather than an orphaned background task.
+func linkFromEvent(event Event) (trace.Link, bool) {
+	if event.TraceID == "" || event.SpanID == "" {
+		return trace.Link{}, false
+	}
+
+	traceIDBytes, err := hex.DecodeString(event.TraceID)
+	if err != nil || len(traceIDBytes) != 16 {
+		return trace.Link{}, false
+	}
+	spanIDBytes, err := hex.DecodeString(event.SpanID)
+	if err != nil || len(spanIDBytes) != 8 {
+		return trace.Link{}, false
+	}
+
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	copy(traceID[:], traceIDBytes)
+	copy(spanID[:], spanIDBytes)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	return trace.Link{SpanContext: sc}, true
+}
@@ -0,0 +1,246 @@
+// Package events implements a durable outbox for order-side saga steps.
+//
+// When CreateOrder cannot reserve inventory synchronously, it records the
+// intent as a row in order_events (in the same transaction as the order
+// insert) instead of dropping it. A background Dispatcher later replays
+// those intents against the inventory service and compensates the order
+// if reservation ultimately fails.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Type identifies the kind of saga event stored in the outbox.
+type Type string
+
+const (
+	TypeReservationPending Type = "RESERVATION_PENDING"
+	TypeOrderCancelled     Type = "ORDER_CANCELLED"
+)
+
+// Status tracks where an event is in its dispatch lifecycle.
+type Status string
+
+const (
+	StatusPending    Status = "PENDING"
+	StatusDispatched Status = "DISPATCHED"
+	StatusFailed     Status = "FAILED"
+)
+
+// ReservationPayload is the JSON payload stored for a RESERVATION_PENDING event.
+type ReservationPayload struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Event is a single row of the order_events outbox table.
+type Event struct {
+	ID            string          `json:"id"`
+	OrderID       string          `json:"order_id"`
+	EventType     Type            `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        Status          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	TraceID       string          `json:"trace_id,omitempty"`
+	SpanID        string          `json:"span_id,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// Store persists and queries the order_events outbox.
+type Store struct {
+	db *pgxpool.Pool
+}
+
+// NewStore creates an outbox Store backed by the orders database pool.
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+// EnqueueReservation inserts a RESERVATION_PENDING event inside tx, the same
+// transaction used to insert the owning order row. traceID/spanID (may be
+// empty) let the dispatcher later link its span back to the request that
+// created the order.
+func (s *Store) EnqueueReservation(ctx context.Context, tx pgx.Tx, orderID, productID string, quantity int, traceID, spanID string) error {
+	payload, err := json.Marshal(ReservationPayload{ProductID: productID, Quantity: quantity})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO order_events (order_id, event_type, payload, status, trace_id, span_id)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		orderID, TypeReservationPending, payload, StatusPending, traceID, spanID,
+	)
+	return err
+}
+
+// ListByOrder returns all events recorded for an order, most recent first.
+func (s *Store) ListByOrder(ctx context.Context, orderID string) ([]Event, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT id, order_id, event_type, payload, status, attempts,
+		        COALESCE(trace_id, ''), COALESCE(span_id, ''), next_attempt_at, created_at, updated_at
+		 FROM order_events
+		 WHERE order_id = $1
+		 ORDER BY created_at DESC`,
+		orderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.EventType, &e.Payload, &e.Status, &e.Attempts,
+			&e.TraceID, &e.SpanID, &e.NextAttemptAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// claimPending begins a transaction and, inside it, fetches up to limit due
+// events, locking them for this dispatcher run and skipping rows already
+// locked by another instance. The returned tx is left open with those locks
+// held - the caller must process every event and record its outcome
+// (markDispatched/markFailed/reschedule) through the same tx, then commit,
+// so a row's lock isn't released until the whole unit of work is done. A
+// lock released as soon as this query returned would let a second
+// dispatcher instance immediately re-claim and double-process the same
+// event.
+func (s *Store) claimPending(ctx context.Context, limit int) (pgx.Tx, []Event, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT id, order_id, event_type, payload, status, attempts,
+		        COALESCE(trace_id, ''), COALESCE(span_id, ''), next_attempt_at, created_at, updated_at
+		 FROM order_events
+		 WHERE status = $1 AND next_attempt_at <= NOW()
+		 ORDER BY created_at
+		 LIMIT $2
+		 FOR UPDATE SKIP LOCKED`,
+		StatusPending, limit,
+	)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, nil, err
+	}
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.EventType, &e.Payload, &e.Status, &e.Attempts,
+			&e.TraceID, &e.SpanID, &e.NextAttemptAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			rows.Close()
+			_ = tx.Rollback(ctx)
+			return nil, nil, err
+		}
+		events = append(events, e)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, nil, err
+	}
+	return tx, events, nil
+}
+
+// hasPendingSiblings reports whether orderID has any other RESERVATION_PENDING
+// event still in StatusPending, excluding eventID itself. The dispatcher uses
+// this to tell a degraded multi-item order's last successful reservation from
+// one of several still in flight, so it only confirms the order once every
+// item has cleared.
+func (s *Store) hasPendingSiblings(ctx context.Context, tx pgx.Tx, orderID, eventID string) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM order_events
+			WHERE order_id = $1 AND event_type = $2 AND status = $3 AND id != $4
+		)`,
+		orderID, TypeReservationPending, StatusPending, eventID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// dispatchedSiblingReservations returns the reservation payloads of orderID's
+// other RESERVATION_PENDING events that already reserved stock (StatusDispatched),
+// excluding eventID itself. compensate uses this to release the inventory those
+// siblings claimed before cancelling an order over a later item's permanent
+// failure.
+func (s *Store) dispatchedSiblingReservations(ctx context.Context, tx pgx.Tx, orderID, eventID string) ([]ReservationPayload, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT payload FROM order_events
+		 WHERE order_id = $1 AND event_type = $2 AND status = $3 AND id != $4`,
+		orderID, TypeReservationPending, StatusDispatched, eventID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payloads []ReservationPayload
+	for rows.Next() {
+		var raw json.RawMessage
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var payload ReservationPayload
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, rows.Err()
+}
+
+func (s *Store) markDispatched(ctx context.Context, tx pgx.Tx, eventID string) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE order_events SET status = $1, updated_at = NOW() WHERE id = $2`,
+		StatusDispatched, eventID,
+	)
+	return err
+}
+
+func (s *Store) markFailed(ctx context.Context, tx pgx.Tx, eventID string) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE order_events SET status = $1, updated_at = NOW() WHERE id = $2`,
+		StatusFailed, eventID,
+	)
+	return err
+}
+
+func (s *Store) reschedule(ctx context.Context, tx pgx.Tx, eventID string, nextAttemptAt time.Time) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE order_events SET attempts = attempts + 1, next_attempt_at = $1, updated_at = NOW() WHERE id = $2`,
+		nextAttemptAt, eventID,
+	)
+	return err
+}
+
+// recordCancellation inserts the compensating ORDER_CANCELLED event for orderID.
+func (s *Store) recordCancellation(ctx context.Context, tx pgx.Tx, orderID, reason string) error {
+	payload, err := json.Marshal(map[string]string{"reason": reason})
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO order_events (order_id, event_type, payload, status)
+		 VALUES ($1, $2, $3, $4)`,
+		orderID, TypeOrderCancelled, payload, StatusDispatched,
+	)
+	return err
+}
@@ -0,0 +1,120 @@
+// Package grpcserver exposes OrdersHandler's order placement/lookup logic
+// over gRPC, running alongside the chi HTTP server. Run `make proto` (from
+// the repo root) to (re)generate the ordersspb stubs this package depends on.
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/rafidoth/orders-service/handlers"
+	"github.com/rafidoth/orders-service/proto/ordersspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server adapts *handlers.OrdersHandler to the generated OrdersServiceServer
+// interface.
+type Server struct {
+	ordersspb.UnimplementedOrdersServiceServer
+	handler *handlers.OrdersHandler
+}
+
+// New builds a gRPC OrdersService server backed by the same handler used by
+// the HTTP transport.
+func New(handler *handlers.OrdersHandler) *Server {
+	return &Server{handler: handler}
+}
+
+// Register wires the OrdersService and standard grpc.health.v1 health
+// service onto grpcServer.
+func (s *Server) Register(grpcServer *grpc.Server) {
+	ordersspb.RegisterOrdersServiceServer(grpcServer, s)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders.OrdersService", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+}
+
+// Serve starts grpcServer on addr and blocks until it stops or ctx is done.
+func Serve(ctx context.Context, grpcServer *grpc.Server, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- grpcServer.Serve(lis) }()
+
+	select {
+	case <-ctx.Done():
+		grpcServer.GracefulStop()
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (s *Server) CreateOrder(ctx context.Context, req *ordersspb.CreateOrderRequest) (*ordersspb.CreateOrderResponse, error) {
+	items := make([]handlers.OrderItem, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		items = append(items, handlers.OrderItem{ProductID: item.GetProductId(), Quantity: int(item.GetQuantity())})
+	}
+	if len(items) == 0 && req.GetProductId() != "" {
+		items = append(items, handlers.OrderItem{ProductID: req.GetProductId(), Quantity: int(req.GetQuantity())})
+	}
+
+	order, message, httpStatus, err := s.handler.CreateOrderCore(ctx, req.GetUserId(), items)
+	if err != nil {
+		return nil, status.Error(httpStatusToGRPC(httpStatus), err.Error())
+	}
+
+	return &ordersspb.CreateOrderResponse{
+		Order:   toProto(*order),
+		Message: message,
+	}, nil
+}
+
+func (s *Server) GetOrder(ctx context.Context, req *ordersspb.GetOrderRequest) (*ordersspb.Order, error) {
+	order, err := s.handler.GetOrderCore(ctx, req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return toProto(*order), nil
+}
+
+func toProto(o handlers.Order) *ordersspb.Order {
+	items := make([]*ordersspb.OrderItem, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, &ordersspb.OrderItem{ProductId: item.ProductID, Quantity: int32(item.Quantity)})
+	}
+
+	return &ordersspb.Order{
+		Id:        o.ID,
+		UserId:    o.UserID,
+		ProductId: o.ProductID,
+		Quantity:  int32(o.Quantity),
+		Status:    o.Status,
+		Items:     items,
+		CreatedAt: timestamppb.New(o.CreatedAt),
+		UpdatedAt: timestamppb.New(o.UpdatedAt),
+	}
+}
+
+func httpStatusToGRPC(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
@@ -4,21 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/rafidoth/orders-service/clients"
+	"github.com/rafidoth/orders-service/httpx"
 	"github.com/rafidoth/orders-service/resilience"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CreateOrderRequest struct {
-	UserID    string `json:"user_id"`
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
+	UserID string `json:"user_id"`
+	// ProductID/Quantity are kept for single-item callers; when Items is
+	// non-empty it takes precedence and ProductID/Quantity are ignored.
+	ProductID string      `json:"product_id,omitempty"`
+	Quantity  int         `json:"quantity,omitempty"`
+	Items     []OrderItem `json:"items,omitempty"`
 }
 
 type CreateOrderResponse struct {
@@ -29,119 +36,253 @@ type CreateOrderResponse struct {
 // Default timeout for inventory operations
 const inventoryTimeout = 3 * time.Second
 
+// ValidationFieldsError carries every field violation found while validating
+// a create-order request, so the HTTP front end can render them as a single
+// structured response instead of a plain-text 400. The gRPC front end falls
+// back to Error() for its status message, since gRPC has no equivalent body.
+type ValidationFieldsError struct {
+	Fields []httpx.FieldError
+}
+
+// orderCreatedEvent is the payload published for the order.created outbox
+// event, carrying enough of the order to let other services react without a
+// follow-up call back to orders.
+type orderCreatedEvent struct {
+	OrderID string      `json:"order_id"`
+	UserID  string      `json:"user_id"`
+	Status  string      `json:"status"`
+	Items   []OrderItem `json:"items"`
+}
+
+func (e *ValidationFieldsError) Error() string {
+	msg := "validation failed"
+	for i, f := range e.Fields {
+		if i == 0 {
+			msg += ": "
+		} else {
+			msg += "; "
+		}
+		msg += f.Field + " " + f.Message
+	}
+	return msg
+}
+
 func (h *OrdersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
-	tracer := otel.Tracer("orders-service")
-	ctx, span := tracer.Start(r.Context(), "CreateOrder")
-	defer span.End()
+	rawBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("Failed to read request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
 
 	var req CreateOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(rawBody, &req); err != nil {
 		slog.Error("Failed to decode request body", "error", err)
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "invalid request body")
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey != "" {
+		h.createOrderIdempotent(w, r, &req, idempotencyKey, rawBody)
+		return
+	}
+
+	h.createOrder(w, r, &req)
+}
+
+// createOrder runs CreateOrderCore and writes its result, with no
+// idempotency replay - the path taken when the caller sent no
+// Idempotency-Key header.
+func (h *OrdersHandler) createOrder(w http.ResponseWriter, r *http.Request, req *CreateOrderRequest) {
+	items := req.Items
+	if len(items) == 0 && req.ProductID != "" {
+		items = []OrderItem{{ProductID: req.ProductID, Quantity: req.Quantity}}
+	}
+
+	order, message, httpStatus, err := h.CreateOrderCore(r.Context(), req.UserID, items)
+	if err != nil {
+		var valErr *ValidationFieldsError
+		if errors.As(err, &valErr) {
+			httpx.ValidationErrors(w, valErr.Fields)
+			return
+		}
+		http.Error(w, err.Error(), httpStatus)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(CreateOrderResponse{Order: *order, Message: message})
+}
+
+// createOrderIdempotent replays the stored response for (user_id,
+// idempotencyKey) if one exists and matches rawBody's hash, rejects the
+// request with 422 if the body doesn't match, and otherwise runs
+// CreateOrderCore and stores its response for future retries. Unlike
+// payment's processPaymentIdempotent, the lookup and the store aren't
+// wrapped in one transaction around CreateOrderCore, since CreateOrderCore
+// calls out to the inventory service (and possibly the saga coordinator)
+// rather than doing a single local database write.
+func (h *OrdersHandler) createOrderIdempotent(w http.ResponseWriter, r *http.Request, req *CreateOrderRequest, idempotencyKey string, rawBody []byte) {
+	ctx := r.Context()
+	requestHash := hashOrderRequestBody(rawBody)
+
+	existing, storedHash, err := lookupOrderIdempotency(ctx, h.db, req.UserID, idempotencyKey)
+	if err != nil {
+		slog.Error("Failed to look up idempotency key", "error", err)
+		http.Error(w, "Failed to create order", http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		if storedHash != requestHash {
+			http.Error(w, errOrderIdempotencyKeyReplayMismatch.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(existing.Status)
+		w.Write(existing.Body)
+		return
+	}
+
+	items := req.Items
+	if len(items) == 0 && req.ProductID != "" {
+		items = []OrderItem{{ProductID: req.ProductID, Quantity: req.Quantity}}
+	}
+
+	order, message, httpStatus, coreErr := h.CreateOrderCore(ctx, req.UserID, items)
+
+	var responseBody []byte
+	if coreErr != nil {
+		// Validation failures aren't stored: they're a property of this
+		// particular request body, not the downstream side effects an
+		// idempotency key exists to protect, and httpx.ValidationErrors'
+		// structured field-error format doesn't round-trip through the
+		// plain status/body pair stored below.
+		var valErr *ValidationFieldsError
+		if errors.As(coreErr, &valErr) {
+			httpx.ValidationErrors(w, valErr.Fields)
+			return
+		}
+		// response_body is JSONB, so the error is stored (and written below)
+		// as a JSON object rather than plain text.
+		responseBody, _ = json.Marshal(map[string]string{"error": coreErr.Error()})
+	} else {
+		responseBody, err = json.Marshal(CreateOrderResponse{Order: *order, Message: message})
+		if err != nil {
+			slog.Error("Failed to marshal order response", "error", err)
+			http.Error(w, "Failed to create order", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := storeOrderIdempotency(ctx, h.db, req.UserID, idempotencyKey, requestHash, httpStatus, responseBody); err != nil {
+		slog.Error("Failed to store idempotency record", "error", err)
+		http.Error(w, "Failed to create order", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(responseBody)
+}
+
+// CreateOrderCore holds the transport-agnostic order placement logic shared by
+// the HTTP and gRPC front ends: attempt a synchronous inventory reservation,
+// fall back to degraded (async, outbox-driven) mode on transient failures,
+// and persist the order. httpStatus is returned even on success (201) so
+// the gRPC server can translate it to the equivalent status code.
+func (h *OrdersHandler) CreateOrderCore(ctx context.Context, userID string, items []OrderItem) (*Order, string, int, error) {
+	tracer := otel.Tracer("orders-service")
+	ctx, span := tracer.Start(ctx, "CreateOrder")
+	defer span.End()
+
 	span.SetAttributes(
-		attribute.String("user_id", req.UserID),
-		attribute.String("product_id", req.ProductID),
-		attribute.Int("quantity", req.Quantity),
+		attribute.String("user_id", userID),
+		attribute.Int("items.count", len(items)),
 	)
 
-	if req.UserID == "" || req.ProductID == "" || req.Quantity <= 0 {
-		slog.Warn("Invalid order request", "user_id", req.UserID, "product_id", req.ProductID, "quantity", req.Quantity)
+	var violations []httpx.FieldError
+	if userID == "" {
+		violations = append(violations, httpx.FieldError{Field: "user_id", Message: "is required"})
+	}
+	if len(items) == 0 {
+		violations = append(violations, httpx.FieldError{Field: "items", Message: "at least one item is required"})
+	}
+	for i, item := range items {
+		prefix := fmt.Sprintf("items[%d].", i)
+		if item.ProductID == "" {
+			violations = append(violations, httpx.FieldError{Field: prefix + "product_id", Message: "is required"})
+		}
+		if item.Quantity <= 0 {
+			violations = append(violations, httpx.FieldError{Field: prefix + "quantity", Message: "must be > 0"})
+		}
+	}
+	if len(violations) > 0 {
+		slog.Warn("Invalid order request", "user_id", userID, "items", len(items), "fields", httpx.FieldNames(violations))
+		span.SetAttributes(attribute.StringSlice("validation.fields", httpx.FieldNames(violations)))
 		span.SetStatus(codes.Error, "validation failed")
-		http.Error(w, "user_id, product_id, and quantity (>0) are required", http.StatusBadRequest)
-		return
+		return nil, "", http.StatusBadRequest, &ValidationFieldsError{Fields: violations}
 	}
 
 	// Determine initial order status and try to reserve inventory
 	orderStatus := "PENDING"
 	message := ""
 	degradedMode := false
+	reservedSingleItem := false
 
-	// Try to reserve inventory with timeout
 	if h.inventoryClient != nil {
 		inventoryCtx, cancel := context.WithTimeout(ctx, inventoryTimeout)
 		defer cancel()
 
-		_, inventorySpan := tracer.Start(inventoryCtx, "CreateOrder.ReserveInventory")
-		inventorySpan.SetAttributes(
-			attribute.String("product_id", req.ProductID),
-			attribute.Int("quantity", req.Quantity),
-		)
-
-		slog.Info("Attempting to reserve inventory",
-			"product_id", req.ProductID,
-			"quantity", req.Quantity,
-		)
-
-		reserveResp, err := h.inventoryClient.ReserveProduct(inventoryCtx, req.ProductID, req.Quantity)
-
-		if err != nil {
-			inventorySpan.RecordError(err)
-
-			// Check for graceful degradation scenarios
-			if errors.Is(err, resilience.ErrCircuitOpen) {
-				slog.Warn("Circuit breaker is open, accepting order in degraded mode",
-					"product_id", req.ProductID,
-					"error", err,
-				)
-				inventorySpan.SetStatus(codes.Error, "circuit breaker open")
-				orderStatus = "PENDING_INVENTORY"
-				message = "Order accepted. Inventory will be reserved when service is available."
-				degradedMode = true
-			} else if errors.Is(err, context.DeadlineExceeded) || inventoryCtx.Err() != nil {
-				slog.Warn("Inventory service timeout, accepting order in degraded mode",
-					"product_id", req.ProductID,
-					"error", err,
-				)
-				inventorySpan.SetStatus(codes.Error, "timeout")
-				orderStatus = "PENDING_INVENTORY"
-				message = "Order accepted. Inventory will be reserved shortly."
-				degradedMode = true
-			} else if errors.Is(err, clients.ErrInsufficientStock) {
-				slog.Warn("Insufficient stock for order",
-					"product_id", req.ProductID,
-					"quantity", req.Quantity,
-				)
-				inventorySpan.SetStatus(codes.Error, "insufficient stock")
-				inventorySpan.End()
-				span.SetStatus(codes.Error, "insufficient stock")
-				http.Error(w, "Insufficient stock available for this product", http.StatusConflict)
-				return
-			} else if errors.Is(err, clients.ErrProductNotFound) {
-				slog.Warn("Product not found",
-					"product_id", req.ProductID,
-				)
-				inventorySpan.SetStatus(codes.Error, "product not found")
-				inventorySpan.End()
-				span.SetStatus(codes.Error, "product not found")
-				http.Error(w, "Product not found", http.StatusNotFound)
-				return
-			} else {
-				// Other errors - still accept in degraded mode
-				slog.Warn("Inventory service error, accepting order in degraded mode",
-					"product_id", req.ProductID,
-					"error", err,
-				)
-				inventorySpan.SetStatus(codes.Error, "inventory service error")
-				orderStatus = "PENDING_INVENTORY"
-				message = "Order accepted. Inventory reservation will be retried."
-				degradedMode = true
-			}
+		var reserveErr error
+		if len(items) == 1 {
+			reserveErr = h.reserveSingle(ctx, inventoryCtx, tracer, items[0])
 		} else {
-			// Success! Inventory reserved
-			slog.Info("Inventory reserved successfully",
-				"product_id", req.ProductID,
-				"quantity", req.Quantity,
-				"available_quantity", reserveResp.AvailableQuantity,
-			)
-			inventorySpan.SetStatus(codes.Ok, "inventory reserved")
+			reserveErr = h.reserveBatch(ctx, inventoryCtx, tracer, items)
+		}
+
+		switch {
+		case reserveErr == nil:
+			slog.Info("Inventory reserved successfully", "items", len(items))
 			orderStatus = "CONFIRMED"
+			reservedSingleItem = len(items) == 1
+		case errors.Is(reserveErr, resilience.ErrCircuitOpen):
+			slog.Warn("Circuit breaker is open, accepting order in degraded mode", "error", reserveErr)
+			orderStatus = "PENDING_INVENTORY"
+			message = "Order accepted. Inventory will be reserved when service is available."
+			degradedMode = true
+		case errors.Is(reserveErr, context.DeadlineExceeded) || inventoryCtx.Err() != nil:
+			slog.Warn("Inventory service timeout, accepting order in degraded mode", "error", reserveErr)
+			orderStatus = "PENDING_INVENTORY"
+			message = "Order accepted. Inventory will be reserved shortly."
+			degradedMode = true
+		case errors.Is(reserveErr, clients.ErrInsufficientStock):
+			slog.Warn("Insufficient stock for order", "items", len(items))
+			span.SetStatus(codes.Error, "insufficient stock")
+			return nil, "", http.StatusConflict, errors.New("Insufficient stock available for this product")
+		case errors.Is(reserveErr, clients.ErrProductNotFound):
+			slog.Warn("Product not found", "items", len(items))
+			span.SetStatus(codes.Error, "product not found")
+			return nil, "", http.StatusNotFound, errors.New("Product not found")
+		default:
+			var batchErr *clients.BatchReservationError
+			if errors.As(reserveErr, &batchErr) {
+				// A batch failure is a business-level rejection (some lines
+				// had insufficient stock or an unknown product), not a
+				// transient error, so the whole order is rejected rather
+				// than partially accepted - there's no line-item status on
+				// Order yet to track a partial fulfillment.
+				slog.Warn("Batch reservation rejected", "failures", batchErr.Failures)
+				span.SetStatus(codes.Error, "batch reservation failed")
+				return nil, "", http.StatusConflict, batchErr
+			}
+
+			slog.Warn("Inventory service error, accepting order in degraded mode", "error", reserveErr)
+			orderStatus = "PENDING_INVENTORY"
+			message = "Order accepted. Inventory reservation will be retried."
+			degradedMode = true
 		}
-		inventorySpan.End()
 	}
 
 	span.SetAttributes(
@@ -149,21 +290,112 @@ func (h *OrdersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		attribute.Bool("degraded_mode", degradedMode),
 	)
 
-	// Create the order in the database
-	_, dbSpan := tracer.Start(ctx, "CreateOrder.DatabaseInsert")
+	var order *Order
+	var err error
+	if reservedSingleItem && h.sagaCoordinator != nil {
+		order, err = h.createOrderWithSaga(ctx, span, userID, items, orderStatus)
+	} else {
+		order, err = h.insertOrder(ctx, span, userID, items, orderStatus, degradedMode)
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to create order")
+		return nil, "", http.StatusInternalServerError, err
+	}
+
+	slog.Info("Order created successfully",
+		"order_id", order.ID,
+		"user_id", order.UserID,
+		"status", order.Status,
+		"degraded_mode", degradedMode,
+	)
+
+	span.SetStatus(codes.Ok, "order created")
+	return order, message, http.StatusCreated, nil
+}
+
+// reserveSingle reserves the one item of a single-product order, preserving
+// the exact ReserveProduct error semantics (insufficient stock vs not found)
+// that CreateOrderCore branches on.
+func (h *OrdersHandler) reserveSingle(ctx, inventoryCtx context.Context, tracer trace.Tracer, item OrderItem) error {
+	_, inventorySpan := tracer.Start(inventoryCtx, "CreateOrder.ReserveInventory")
+	defer inventorySpan.End()
+	inventorySpan.SetAttributes(
+		attribute.String("product_id", item.ProductID),
+		attribute.Int("quantity", item.Quantity),
+	)
+
+	slog.Info("Attempting to reserve inventory", "product_id", item.ProductID, "quantity", item.Quantity)
+
+	reserveResp, err := h.inventoryClient.ReserveProduct(inventoryCtx, item.ProductID, item.Quantity)
+	if err != nil {
+		inventorySpan.RecordError(err)
+		inventorySpan.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	inventorySpan.SetAttributes(attribute.Int("available_quantity", reserveResp.AvailableQuantity))
+	inventorySpan.SetStatus(codes.Ok, "inventory reserved")
+	return nil
+}
+
+// reserveBatch reserves every item of a multi-product order atomically via
+// the inventory service's bulk endpoint.
+func (h *OrdersHandler) reserveBatch(ctx, inventoryCtx context.Context, tracer trace.Tracer, items []OrderItem) error {
+	_, inventorySpan := tracer.Start(inventoryCtx, "CreateOrder.ReserveBatch")
+	defer inventorySpan.End()
+	inventorySpan.SetAttributes(attribute.Int("items.count", len(items)))
+
+	batchItems := make([]clients.BatchItem, len(items))
+	for i, item := range items {
+		batchItems[i] = clients.BatchItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	slog.Info("Attempting batch inventory reservation", "items", len(items))
+
+	_, err := h.inventoryClient.ReserveBatch(inventoryCtx, batchItems)
+	if err != nil {
+		inventorySpan.RecordError(err)
+		inventorySpan.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	inventorySpan.SetStatus(codes.Ok, "batch reserved")
+	return nil
+}
+
+// insertOrder creates the order row (and its order_items lines), and - when
+// inventory reservation was deferred - one outbox event per item, all in a
+// single transaction so the saga intent can never be silently dropped.
+func (h *OrdersHandler) insertOrder(ctx context.Context, span trace.Span, userID string, items []OrderItem, orderStatus string, degradedMode bool) (*Order, error) {
+	tracer := otel.Tracer("orders-service")
+	ctx, dbSpan := tracer.Start(ctx, "CreateOrder.DatabaseInsert")
+	defer dbSpan.End()
 	dbSpan.SetAttributes(
 		attribute.String("db.system", "postgresql"),
 		attribute.String("db.operation", "INSERT"),
 		attribute.String("db.table", "orders"),
 	)
 
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to start order transaction", "error", err)
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, "failed to begin transaction")
+		return nil, errors.New("failed to create order")
+	}
+	defer tx.Rollback(ctx)
+
+	// orders.product_id/quantity are retained for single-item compatibility
+	// and reflect the first line of the order.
+	primary := items[0]
+
 	var order Order
-	err := h.db.QueryRow(
-		r.Context(),
+	err = tx.QueryRow(
+		ctx,
 		`INSERT INTO orders (user_id, product_id, quantity, status)
 		 VALUES ($1, $2, $3, $4)
 		 RETURNING id, user_id, product_id, quantity, status, created_at, updated_at`,
-		req.UserID, req.ProductID, req.Quantity, orderStatus,
+		userID, primary.ProductID, primary.Quantity, orderStatus,
 	).Scan(&order.ID, &order.UserID, &order.ProductID, &order.Quantity,
 		&order.Status, &order.CreatedAt, &order.UpdatedAt)
 
@@ -171,26 +403,57 @@ func (h *OrdersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		slog.Error("Failed to create order", "error", err)
 		dbSpan.RecordError(err)
 		dbSpan.SetStatus(codes.Error, "database insert failed")
-		dbSpan.End()
-		span.SetStatus(codes.Error, "failed to create order")
-		http.Error(w, "Failed to create order: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, errors.New("failed to create order: " + err.Error())
 	}
 
-	dbSpan.SetAttributes(attribute.String("order.id", order.ID))
-	dbSpan.SetStatus(codes.Ok, "order created")
-	dbSpan.End()
+	for _, item := range items {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO order_items (order_id, product_id, quantity) VALUES ($1, $2, $3)`,
+			order.ID, item.ProductID, item.Quantity,
+		); err != nil {
+			slog.Error("Failed to insert order item", "order_id", order.ID, "error", err)
+			dbSpan.RecordError(err)
+			dbSpan.SetStatus(codes.Error, "failed to insert order item")
+			return nil, errors.New("failed to create order")
+		}
+	}
+	order.Items = items
 
-	slog.Info("Order created successfully",
-		"order_id", order.ID,
-		"user_id", order.UserID,
-		"status", order.Status,
-		"degraded_mode", degradedMode,
-	)
+	if degradedMode && h.eventsStore != nil {
+		spanCtx := span.SpanContext()
+		for _, item := range items {
+			if err := h.eventsStore.EnqueueReservation(ctx, tx, order.ID, item.ProductID, item.Quantity,
+				spanCtx.TraceID().String(), spanCtx.SpanID().String()); err != nil {
+				slog.Error("Failed to enqueue reservation event", "order_id", order.ID, "error", err)
+				dbSpan.RecordError(err)
+				dbSpan.SetStatus(codes.Error, "failed to enqueue reservation event")
+				return nil, errors.New("failed to create order")
+			}
+		}
+	}
 
-	span.SetStatus(codes.Ok, "order created")
+	if h.outboxStore != nil {
+		if err := h.outboxStore.Enqueue(ctx, tx, order.ID, "order.created", orderCreatedEvent{
+			OrderID: order.ID,
+			UserID:  order.UserID,
+			Status:  order.Status,
+			Items:   items,
+		}, span); err != nil {
+			slog.Error("Failed to enqueue order.created outbox event", "order_id", order.ID, "error", err)
+			dbSpan.RecordError(err)
+			dbSpan.SetStatus(codes.Error, "failed to enqueue outbox event")
+			return nil, errors.New("failed to create order")
+		}
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(CreateOrderResponse{Order: order, Message: message})
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("Failed to commit order transaction", "error", err)
+		dbSpan.RecordError(err)
+		dbSpan.SetStatus(codes.Error, "failed to commit transaction")
+		return nil, errors.New("failed to create order")
+	}
+
+	dbSpan.SetAttributes(attribute.String("order.id", order.ID))
+	dbSpan.SetStatus(codes.Ok, "order created")
+	return &order, nil
 }
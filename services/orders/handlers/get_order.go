@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -15,9 +17,26 @@ func (h *OrdersHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	order, err := h.GetOrderCore(r.Context(), orderID)
+	if err != nil {
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetOrderCore is the transport-agnostic order lookup shared by the HTTP and
+// gRPC front ends.
+func (h *OrdersHandler) GetOrderCore(ctx context.Context, orderID string) (*Order, error) {
+	if orderID == "" {
+		return nil, errors.New("order id is required")
+	}
+
 	var order Order
 	err := h.db.QueryRow(
-		r.Context(),
+		ctx,
 		`SELECT id, user_id, product_id, quantity, status, created_at, updated_at
 		 FROM orders WHERE id = $1`,
 		orderID,
@@ -26,12 +45,38 @@ func (h *OrdersHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		slog.Warn("Order not found", "order_id", orderID, "error", err)
-		http.Error(w, "Order not found", http.StatusNotFound)
-		return
+		return nil, err
+	}
+
+	items, err := h.listOrderItems(ctx, orderID)
+	if err != nil {
+		slog.Warn("Failed to load order items", "order_id", orderID, "error", err)
+		return nil, err
 	}
+	order.Items = items
 
 	slog.Info("Order retrieved", "order_id", order.ID)
+	return &order, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(order)
+// listOrderItems loads the line items of an order, in insertion order.
+func (h *OrdersHandler) listOrderItems(ctx context.Context, orderID string) ([]OrderItem, error) {
+	rows, err := h.db.Query(ctx,
+		`SELECT product_id, quantity FROM order_items WHERE order_id = $1 ORDER BY created_at`,
+		orderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []OrderItem
+	for rows.Next() {
+		var item OrderItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
 }
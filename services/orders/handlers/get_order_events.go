@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetOrderEvents exposes the order_events outbox rows for an order, so the
+// saga state (pending/dispatched/failed reservation attempts) can be
+// inspected without querying Postgres directly.
+func (h *OrdersHandler) GetOrderEvents(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	if orderID == "" {
+		http.Error(w, "Order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if h.eventsStore == nil {
+		http.Error(w, "Order events are not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	events, err := h.eventsStore.ListByOrder(r.Context(), orderID)
+	if err != nil {
+		slog.Error("Failed to list order events", "order_id", orderID, "error", err)
+		http.Error(w, "Failed to retrieve order events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rafidoth/orders-service/clients"
+	"github.com/rafidoth/orders-service/middleware"
+)
+
+// GetOrderPaymentStatus proxies to the payment service for the payment
+// recorded against an order, going through the resilient PaymentClient so a
+// slow or unhealthy payment service fails fast here instead of piling up.
+func (h *OrdersHandler) GetOrderPaymentStatus(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	if orderID == "" {
+		http.Error(w, "Order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if h.paymentClient == nil {
+		http.Error(w, "Payment service is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	order, err := h.GetOrderCore(r.Context(), orderID)
+	if err != nil {
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	}
+
+	if !isOwnerOrAdmin(r.Context(), order.UserID) {
+		http.Error(w, "You do not have access to this order's payment", http.StatusForbidden)
+		return
+	}
+
+	status, err := h.paymentClient.GetPaymentByOrderID(r.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, clients.ErrPaymentNotFound) {
+			http.Error(w, "Payment not found for this order", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch payment status", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// isOwnerOrAdmin reports whether the authenticated caller is ownerUserID or
+// holds the admin role, mirroring the check the payment service applies to
+// its own payment routes.
+func isOwnerOrAdmin(ctx context.Context, ownerUserID string) bool {
+	if userID, ok := middleware.UserID(ctx); ok && userID == ownerUserID {
+		return true
+	}
+	for _, role := range middleware.Roles(ctx) {
+		if role == "admin" {
+			return true
+		}
+	}
+	return false
+}
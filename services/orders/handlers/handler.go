@@ -5,26 +5,45 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rafidoth/orders-service/clients"
+	"github.com/rafidoth/orders-service/events"
+	"github.com/rafidoth/orders-service/outbox"
+	"github.com/rafidoth/orders-service/saga"
 )
 
 type OrdersHandler struct {
 	db              *pgxpool.Pool
 	inventoryClient *clients.InventoryClient
+	paymentClient   *clients.PaymentClient
+	eventsStore     *events.Store
+	outboxStore     *outbox.Store
+	sagaCoordinator *saga.Coordinator
 }
 
-func New(conn *pgxpool.Pool, inventoryClient *clients.InventoryClient) *OrdersHandler {
+func New(conn *pgxpool.Pool, inventoryClient *clients.InventoryClient, paymentClient *clients.PaymentClient, eventsStore *events.Store, outboxStore *outbox.Store, sagaCoordinator *saga.Coordinator) *OrdersHandler {
 	return &OrdersHandler{
 		db:              conn,
 		inventoryClient: inventoryClient,
+		paymentClient:   paymentClient,
+		eventsStore:     eventsStore,
+		outboxStore:     outboxStore,
+		sagaCoordinator: sagaCoordinator,
 	}
 }
 
 type Order struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	ProductID string    `json:"product_id"`
-	Quantity  int       `json:"quantity"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"`
+	ProductID string      `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	Status    string      `json:"status"`
+	Items     []OrderItem `json:"items,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// OrderItem is one line of a (possibly multi-product) order, persisted in
+// the order_items table.
+type OrderItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
 }
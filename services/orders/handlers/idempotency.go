@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// orderIdempotencyTTL bounds how long a stored response is replayed for a
+// given (user_id, idempotency_key) pair before the key is eligible for reuse.
+const orderIdempotencyTTL = 24 * time.Hour
+
+// orderIdempotencyCleanupInterval is how often StartIdempotencyCleanup sweeps
+// expired order_idempotency rows.
+const orderIdempotencyCleanupInterval = 1 * time.Hour
+
+// errOrderIdempotencyKeyReplayMismatch means the caller reused an
+// idempotency key with a different request body than the one it was first
+// stored with.
+var errOrderIdempotencyKeyReplayMismatch = errors.New("idempotency key reused with a different request body")
+
+// orderIdempotencyRecord is a previously stored response for an idempotency
+// key, or nil if the key hasn't been seen (or its TTL has expired).
+type orderIdempotencyRecord struct {
+	Status int
+	Body   json.RawMessage
+}
+
+// hashOrderRequestBody fingerprints the raw request body so a replayed
+// idempotency key can be matched against the exact request it was first
+// used with.
+func hashOrderRequestBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// lookupOrderIdempotency checks for a live record under (userID, key).
+// Unlike payment's idempotency check, this doesn't run inside the same
+// transaction as order creation: CreateOrderCore makes an outbound call to
+// the inventory service before it ever touches the database, so there's no
+// single transaction to hold the lookup-and-store inside. A concurrent
+// retry racing the first request through CreateOrderCore is the accepted
+// cost of that.
+func lookupOrderIdempotency(ctx context.Context, db *pgxpool.Pool, userID, key string) (*orderIdempotencyRecord, string, error) {
+	var requestHash string
+	var rec orderIdempotencyRecord
+	var expiresAt time.Time
+
+	err := db.QueryRow(ctx,
+		`SELECT request_hash, response_status, response_body, expires_at
+		 FROM order_idempotency
+		 WHERE user_id = $1 AND idempotency_key = $2`,
+		userID, key,
+	).Scan(&requestHash, &rec.Status, &rec.Body, &expiresAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if time.Now().After(expiresAt) {
+		// Expired: treat as unseen, but keep the hash so the caller can still
+		// detect a body mismatch against the stale record if it wants to.
+		return nil, requestHash, nil
+	}
+	return &rec, requestHash, nil
+}
+
+// storeOrderIdempotency persists the response that was just produced for
+// (userID, key) so a retry within the TTL replays it verbatim.
+func storeOrderIdempotency(ctx context.Context, db *pgxpool.Pool, userID, key, requestHash string, status int, body []byte) error {
+	_, err := db.Exec(ctx,
+		`INSERT INTO order_idempotency (user_id, idempotency_key, request_hash, response_status, response_body, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (user_id, idempotency_key) DO UPDATE
+		 SET request_hash = EXCLUDED.request_hash,
+		     response_status = EXCLUDED.response_status,
+		     response_body = EXCLUDED.response_body,
+		     created_at = NOW(),
+		     expires_at = EXCLUDED.expires_at`,
+		userID, key, requestHash, status, body, time.Now().Add(orderIdempotencyTTL),
+	)
+	return err
+}
+
+// StartIdempotencyCleanup periodically deletes expired order_idempotency
+// rows until ctx is cancelled. It runs as a background goroutine from
+// main.go, the same way outbox.Publisher and events.Dispatcher do - expired
+// rows are otherwise harmless (lookupOrderIdempotency already treats them as
+// unseen) but would accumulate forever without it.
+func StartIdempotencyCleanup(ctx context.Context, db *pgxpool.Pool) {
+	ticker := time.NewTicker(orderIdempotencyCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tag, err := db.Exec(ctx, `DELETE FROM order_idempotency WHERE expires_at < NOW()`)
+			if err != nil {
+				slog.Error("failed to clean up expired order idempotency keys", "error", err)
+				continue
+			}
+			if tag.RowsAffected() > 0 {
+				slog.Info("cleaned up expired order idempotency keys", "count", tag.RowsAffected())
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/rafidoth/orders-service/clients"
+	"github.com/rafidoth/orders-service/saga"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// orderSagaPayload is persisted as the saga's payload, recording enough of
+// the request to explain a stuck saga from the admin endpoint alone.
+type orderSagaPayload struct {
+	UserID    string `json:"user_id"`
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// reserveInventoryStep wraps a reservation that has *already succeeded*
+// (CreateOrderCore calls ReserveProduct itself, before the saga even
+// starts, to preserve its existing degraded-mode branching) so the saga
+// still knows to release it if a later step fails. Run is a no-op for that
+// reason; Compensate does the real work.
+func reserveInventoryStep(inventoryClient *clients.InventoryClient, productID string, quantity int) saga.Step {
+	return saga.Step{
+		Name: "ReserveInventory",
+		Run: func(ctx context.Context) error {
+			return nil
+		},
+		Compensate: func(ctx context.Context) error {
+			return inventoryClient.ReleaseProduct(ctx, productID, quantity)
+		},
+	}
+}
+
+// createOrderStep persists the order itself. It's always the last step in
+// the saga this package builds, so its own Compensate (cancelling the
+// order) is never actually invoked - compensation only runs for steps
+// before the one that failed - but it's implemented anyway so the step
+// pair is complete if a later step is ever appended after it. The caller
+// reads *result.Order once saga.Start returns, since Run populates it only
+// once the coordinator actually executes this step.
+func createOrderStep(h *OrdersHandler, span trace.Span, userID string, items []OrderItem, orderStatus string) (saga.Step, *orderResult) {
+	result := &orderResult{}
+	return saga.Step{
+		Name: "CreateOrder",
+		Run: func(ctx context.Context) error {
+			order, err := h.insertOrder(ctx, span, userID, items, orderStatus, false)
+			if err != nil {
+				return err
+			}
+			result.Order = order
+			return nil
+		},
+		Compensate: func(ctx context.Context) error {
+			if result.Order == nil {
+				return nil
+			}
+			_, err := h.db.Exec(ctx, `UPDATE orders SET status = 'CANCELLED', updated_at = NOW() WHERE id = $1`, result.Order.ID)
+			return err
+		},
+	}, result
+}
+
+// orderResult carries the CreateOrder step's output back to the caller of
+// saga.Start, which only has the step closures to work with until they run.
+type orderResult struct {
+	Order *Order
+}
+
+// createOrderWithSaga persists a single-item, already-reserved order
+// through a two-step saga (ReserveInventory, CreateOrder) so a failure
+// inserting the order releases the reservation instead of leaking it -
+// the gap the saga coordinator exists to close. Multi-item and
+// degraded-mode orders keep going through insertOrder directly: batch
+// reservation has its own all-or-nothing release semantics already, and
+// degraded mode never holds a live reservation to release in the first
+// place.
+func (h *OrdersHandler) createOrderWithSaga(ctx context.Context, span trace.Span, userID string, items []OrderItem, orderStatus string) (*Order, error) {
+	item := items[0]
+
+	reserveStep := reserveInventoryStep(h.inventoryClient, item.ProductID, item.Quantity)
+	orderStep, result := createOrderStep(h, span, userID, items, orderStatus)
+
+	payload := orderSagaPayload{UserID: userID, ProductID: item.ProductID, Quantity: item.Quantity}
+	_, err := h.sagaCoordinator.Start(ctx, "order_placement", payload, []saga.Step{reserveStep, orderStep})
+	if err != nil {
+		return nil, err
+	}
+	return result.Order, nil
+}
+
+// chargePaymentStep charges the order total and refunds it on compensation.
+// It's built for the saga's documented three-pair shape
+// (ReserveInventory/ChargePayment/CreateOrder), but isn't wired into
+// CreateOrderCore's live call path yet: CreateOrderRequest carries no card
+// details today, so charging synchronously during order placement would be
+// an API contract change beyond this request's scope.
+func chargePaymentStep(paymentClient *clients.PaymentClient, req clients.ChargeRequest) saga.Step {
+	var paymentID string
+	return saga.Step{
+		Name: "ChargePayment",
+		Run: func(ctx context.Context) error {
+			status, err := paymentClient.ChargePayment(ctx, req)
+			if status != nil {
+				paymentID = status.ID
+			}
+			return err
+		},
+		Compensate: func(ctx context.Context) error {
+			if paymentID == "" {
+				return nil
+			}
+			return paymentClient.RefundPayment(ctx, paymentID)
+		},
+	}
+}
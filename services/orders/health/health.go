@@ -0,0 +1,156 @@
+// Package health provides the /health/live, /health/ready, and /health
+// endpoints every service registers, backed by a Registry of named
+// Checkers. Each dependency (the database, the broker, a downstream HTTP
+// service) reports its own status, latency, and last error independently,
+// and a checker can be marked non-critical when its failure shouldn't take
+// the whole service out of rotation.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Checker reports whether a single dependency is currently healthy.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// checkFunc adapts a plain function to a Checker.
+type checkFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// NewCheck builds a Checker from a name and a function.
+func NewCheck(name string, fn func(ctx context.Context) error) Checker {
+	return checkFunc{name: name, fn: fn}
+}
+
+func (c checkFunc) Name() string                    { return c.name }
+func (c checkFunc) Check(ctx context.Context) error { return c.fn(ctx) }
+
+type registration struct {
+	checker  Checker
+	critical bool
+	timeout  time.Duration
+}
+
+// defaultTimeout bounds any checker that's registered without one.
+const defaultTimeout = 3 * time.Second
+
+// Registry collects the Checkers a service depends on and serves
+// /health/live, /health/ready, and /health from them.
+type Registry struct {
+	mu   sync.RWMutex
+	regs []registration
+}
+
+// NewRegistry returns an empty Registry ready to have Checkers registered.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds checker to the registry with the given per-check timeout
+// (defaultTimeout if zero). Critical checkers must be healthy for
+// /health/ready to return 200; non-critical checkers are still reported but
+// don't affect readiness.
+func (r *Registry) Register(checker Checker, critical bool, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.regs = append(r.regs, registration{checker: checker, critical: critical, timeout: timeout})
+}
+
+// CheckResult is one checker's outcome in the /health and /health/ready
+// responses.
+type CheckResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+type aggregateReport struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+func (r *Registry) runChecks(ctx context.Context) ([]CheckResult, bool) {
+	r.mu.RLock()
+	regs := make([]registration, len(r.regs))
+	copy(regs, r.regs)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(regs))
+	ready := true
+	for i, reg := range regs {
+		timeout := reg.timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		err := reg.checker.Check(checkCtx)
+		latency := time.Since(start)
+		cancel()
+
+		result := CheckResult{
+			Name:      reg.checker.Name(),
+			Status:    "ok",
+			LatencyMs: latency.Milliseconds(),
+		}
+		if err != nil {
+			result.Status = "failing"
+			result.LastError = err.Error()
+			if reg.critical {
+				ready = false
+			}
+		}
+		results[i] = result
+	}
+	return results, ready
+}
+
+// Live reports that the process is up and handling requests. It never
+// inspects dependency state: Kubernetes uses liveness failures to decide
+// whether to restart the container, and a downstream outage is not a
+// reason to do that.
+func (r *Registry) Live(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Ready reports whether every critical dependency is currently healthy, so
+// Kubernetes stops routing traffic to a pod that can't serve requests.
+func (r *Registry) Ready(w http.ResponseWriter, req *http.Request) {
+	results, ready := r.runChecks(req.Context())
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report(ready, results))
+}
+
+// Health returns the same aggregate report as Ready but always with a 200,
+// for dashboards and manual inspection rather than orchestrator routing.
+func (r *Registry) Health(w http.ResponseWriter, req *http.Request) {
+	results, ready := r.runChecks(req.Context())
+	writeJSON(w, http.StatusOK, report(ready, results))
+}
+
+func report(ready bool, results []CheckResult) aggregateReport {
+	status := "healthy"
+	if !ready {
+		status = "unhealthy"
+	}
+	return aggregateReport{Status: status, Checks: results}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
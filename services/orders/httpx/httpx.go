@@ -0,0 +1,48 @@
+// Package httpx provides structured, field-level validation error responses
+// so API clients can map failures back to form fields instead of parsing
+// free-form strings out of a plain-text 400.
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// FieldError describes one validation violation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// validationErrorBody is an RFC 7807-flavored problem body: a machine-
+// readable error code plus the list of violations that caused it.
+type validationErrorBody struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
+
+// ValidationError responds with a single field violation.
+func ValidationError(w http.ResponseWriter, field, message string) {
+	ValidationErrors(w, []FieldError{{Field: field, Message: message}})
+}
+
+// ValidationErrors responds with every violation collected for the request,
+// so a caller can fix all of them in one round trip instead of one at a time.
+func ValidationErrors(w http.ResponseWriter, fields []FieldError) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(validationErrorBody{
+		Error:  "validation_failed",
+		Fields: fields,
+	})
+}
+
+// FieldNames extracts the field names of a violation list, for attaching to
+// an OTEL span as a debugging attribute.
+func FieldNames(fields []FieldError) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Field
+	}
+	return names
+}
@@ -1,19 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 
+	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rafidoth/orders-service/clients"
 	"github.com/rafidoth/orders-service/config"
 	"github.com/rafidoth/orders-service/db"
+	"github.com/rafidoth/orders-service/events"
 	"github.com/rafidoth/orders-service/handlers"
+	"github.com/rafidoth/orders-service/health"
+	"github.com/rafidoth/orders-service/outbox"
+	"github.com/rafidoth/orders-service/saga"
+	"github.com/rafidoth/orders-service/seeds"
 	"github.com/rafidoth/orders-service/tracing"
 )
 
+// ordersExchange is the topic exchange the outbox publisher ships
+// order.created and related events to; inventory and payment bind their own
+// queues to it for the event types they care about.
+const ordersExchange = "orders.events"
+
 func init() {
 	opts := &slog.HandlerOptions{
 		AddSource: true,
@@ -56,23 +68,116 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize inventory client with circuit breaker protection
+	// Initialize inventory client with circuit breaker protection. The
+	// transport (HTTP or gRPC) is chosen per config so operators can migrate
+	// deployments to gRPC without a code change.
 	slog.Info("Initializing inventory client",
+		"transport", cfg.InventoryTransport,
 		"base_url", cfg.InventoryServiceURL,
+		"grpc_addr", cfg.InventoryGRPCAddr,
 		"timeout_seconds", cfg.Resilience.TimeoutSeconds,
 		"circuit_max_failures", cfg.Resilience.CircuitMaxFailures,
 		"circuit_timeout_seconds", cfg.Resilience.CircuitTimeoutSeconds,
 	)
 
-	inventoryClient := clients.NewInventoryClient(
-		cfg.InventoryServiceURL,
+	var inventoryClient *clients.InventoryClient
+	switch cfg.InventoryTransport {
+	case "grpc":
+		inventoryClient, err = clients.NewInventoryGRPCClient(
+			cfg.InventoryGRPCAddr,
+			time.Duration(cfg.Resilience.TimeoutSeconds)*time.Second,
+			cfg.Resilience.CircuitMaxFailures,
+			time.Duration(cfg.Resilience.CircuitTimeoutSeconds)*time.Second,
+		)
+		if err != nil {
+			slog.Error("unable to create gRPC inventory client", "error", err)
+			os.Exit(1)
+		}
+	default:
+		inventoryClient = clients.NewInventoryClient(
+			cfg.InventoryServiceURL,
+			time.Duration(cfg.Resilience.TimeoutSeconds)*time.Second,
+			cfg.Resilience.CircuitMaxFailures,
+			time.Duration(cfg.Resilience.CircuitTimeoutSeconds)*time.Second,
+		)
+	}
+
+	// The events outbox lets CreateOrder persist a reservation intent
+	// durably instead of dropping it when inventory can't be reserved
+	// synchronously; the dispatcher replays those intents in the background.
+	eventsStore := events.NewStore(conn)
+	dispatcher := events.NewDispatcher(conn, eventsStore, inventoryClient)
+	dispatcherCtx, cancelDispatcher := context.WithCancel(context.Background())
+	defer cancelDispatcher()
+	go dispatcher.Start(dispatcherCtx)
+
+	paymentClient := clients.NewPaymentClient(
+		cfg.PaymentServiceURL,
 		time.Duration(cfg.Resilience.TimeoutSeconds)*time.Second,
 		cfg.Resilience.CircuitMaxFailures,
 		time.Duration(cfg.Resilience.CircuitTimeoutSeconds)*time.Second,
 	)
 
-	handler := handlers.New(conn, inventoryClient)
-	server := NewServer(handler, cfg)
+	// The outbox publisher ships order.created (and future) events to
+	// RabbitMQ so peer services can react without orders calling them
+	// synchronously. Publishing is simply disabled, not fatal, when no
+	// broker URL is configured.
+	outboxStore := outbox.NewStore()
+	var amqpConn *amqp.Connection
+	if cfg.RabbitMqURL != "" {
+		amqpConn, err = amqp.Dial(cfg.RabbitMqURL)
+		if err != nil {
+			slog.Error("unable to connect to RabbitMQ, outbox publishing disabled", "error", err)
+		} else {
+			amqpChannel, err := amqpConn.Channel()
+			if err != nil {
+				slog.Error("unable to open RabbitMQ channel, outbox publishing disabled", "error", err)
+			} else if err := amqpChannel.ExchangeDeclare(ordersExchange, "topic", true, false, false, false, nil); err != nil {
+				slog.Error("unable to declare orders exchange, outbox publishing disabled", "error", err)
+			} else {
+				publisher := outbox.NewPublisher(conn, amqpChannel, ordersExchange)
+				publisherCtx, cancelPublisher := context.WithCancel(context.Background())
+				defer cancelPublisher()
+				go publisher.Start(publisherCtx)
+			}
+		}
+	}
+
+	// The database is a hard dependency; RabbitMQ and the downstream
+	// services are registered for visibility but don't fail readiness,
+	// since CreateOrder already degrades gracefully (via the events store
+	// and circuit breakers) when either is unavailable.
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register(health.DBChecker(conn), true, 0)
+	healthRegistry.Register(health.AMQPChecker(amqpConn), false, 0)
+	healthRegistry.Register(health.HTTPChecker("inventory-service", cfg.InventoryServiceURL), false, 0)
+	healthRegistry.Register(health.HTTPChecker("payment-service", cfg.PaymentServiceURL), false, 0)
+
+	if cfg.SeedPath != "" {
+		count, err := seeds.Load(context.Background(), conn, cfg.SeedPath)
+		if err != nil {
+			slog.Error("Failed to load seed data", "seed_path", cfg.SeedPath, "error", err)
+		} else {
+			slog.Info("Seed data loaded", "seed_path", cfg.SeedPath, "orders", count)
+		}
+	}
+
+	// order_placement is intentionally left unregistered: insertOrder has no
+	// idempotency key, so a crash-resumed retry could double-insert. ResumeAll
+	// leaves a RUNNING saga of an unregistered type alone, so an interrupted
+	// order_placement saga just shows up via the stuck-saga admin endpoint
+	// for manual handling instead.
+	sagaCoordinator := saga.NewCoordinator(conn)
+	if err := sagaCoordinator.ResumeAll(context.Background()); err != nil {
+		slog.Error("failed to resume in-flight sagas", "error", err)
+	}
+
+	idempotencyCleanupCtx, cancelIdempotencyCleanup := context.WithCancel(context.Background())
+	defer cancelIdempotencyCleanup()
+	go handlers.StartIdempotencyCleanup(idempotencyCleanupCtx, conn)
+
+	handler := handlers.New(conn, inventoryClient, paymentClient, eventsStore, outboxStore, sagaCoordinator)
+	sagaAdmin := saga.NewAdminHandler(sagaCoordinator)
+	server := NewServer(handler, healthRegistry, sagaAdmin, cfg)
 	server.Start()
 }
-
@@ -0,0 +1,85 @@
+package resilience
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Stop is returned by Backoff.NextBackOff to signal that no more retries
+// should be attempted.
+const Stop time.Duration = -1
+
+// Backoff computes successive retry delays for a single retry sequence.
+// Reset must be called (or the Backoff freshly constructed) before reusing
+// it for a new sequence - callers that retry concurrently need one Backoff
+// per in-flight operation, not one shared across all of them.
+type Backoff interface {
+	NextBackOff() time.Duration
+	Reset()
+}
+
+// ExponentialBackoff grows each delay by Multiplier, jitters it by
+// RandomizationFactor so concurrent retriers don't all wake up at once, and
+// caps it at MaxInterval. NextBackOff returns Stop once MaxElapsedTime has
+// passed since the last Reset.
+type ExponentialBackoff struct {
+	InitialInterval     time.Duration
+	Multiplier          float64
+	RandomizationFactor float64
+	MaxInterval         time.Duration
+	MaxElapsedTime      time.Duration
+
+	currentInterval time.Duration
+	startTime       time.Time
+}
+
+// NewExponentialBackoff returns an ExponentialBackoff configured with this
+// client's default retry policy: a 100ms initial interval doubling up to
+// 2s, +/-20% jitter, giving up after 5s of total elapsed retry time.
+func NewExponentialBackoff() *ExponentialBackoff {
+	b := &ExponentialBackoff{
+		InitialInterval:     100 * time.Millisecond,
+		Multiplier:          2,
+		RandomizationFactor: 0.2,
+		MaxInterval:         2 * time.Second,
+		MaxElapsedTime:      5 * time.Second,
+	}
+	b.Reset()
+	return b
+}
+
+// Reset starts a fresh retry sequence: the next NextBackOff call returns a
+// jittered InitialInterval, and MaxElapsedTime is measured from now.
+func (b *ExponentialBackoff) Reset() {
+	b.currentInterval = b.InitialInterval
+	b.startTime = time.Now()
+}
+
+// NextBackOff returns the next retry delay, or Stop once MaxElapsedTime has
+// elapsed since the last Reset.
+func (b *ExponentialBackoff) NextBackOff() time.Duration {
+	if b.MaxElapsedTime > 0 && time.Since(b.startTime) > b.MaxElapsedTime {
+		return Stop
+	}
+
+	delay := jitter(b.currentInterval, b.RandomizationFactor)
+
+	next := time.Duration(float64(b.currentInterval) * b.Multiplier)
+	if b.MaxInterval > 0 && next > b.MaxInterval {
+		next = b.MaxInterval
+	}
+	b.currentInterval = next
+
+	return delay
+}
+
+// jitter randomizes interval by +/-factor.
+func jitter(interval time.Duration, factor float64) time.Duration {
+	if factor <= 0 {
+		return interval
+	}
+	delta := factor * float64(interval)
+	low := float64(interval) - delta
+	high := float64(interval) + delta
+	return time.Duration(low + rand.Float64()*(high-low))
+}
@@ -0,0 +1,98 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffGrowsUpToMaxInterval(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval: 10 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     30 * time.Millisecond,
+		MaxElapsedTime:  time.Second,
+	}
+	b.Reset()
+
+	delays := make([]time.Duration, 4)
+	for i := range delays {
+		delays[i] = b.NextBackOff()
+	}
+
+	if delays[0] != 10*time.Millisecond {
+		t.Errorf("expected first delay to be 10ms, got %s", delays[0])
+	}
+	if delays[1] != 20*time.Millisecond {
+		t.Errorf("expected second delay to be 20ms, got %s", delays[1])
+	}
+	if delays[2] != 30*time.Millisecond {
+		t.Errorf("expected third delay to be capped at 30ms, got %s", delays[2])
+	}
+	if delays[3] != 30*time.Millisecond {
+		t.Errorf("expected delay to stay capped at 30ms, got %s", delays[3])
+	}
+}
+
+func TestExponentialBackoffStopsAfterMaxElapsedTime(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval: 5 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     time.Second,
+		MaxElapsedTime:  20 * time.Millisecond,
+	}
+	b.Reset()
+
+	time.Sleep(25 * time.Millisecond)
+
+	if delay := b.NextBackOff(); delay != Stop {
+		t.Errorf("expected Stop after MaxElapsedTime, got %s", delay)
+	}
+}
+
+func TestExponentialBackoffResetStartsOver(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval: 10 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     time.Second,
+		MaxElapsedTime:  time.Second,
+	}
+	b.Reset()
+	b.NextBackOff()
+	b.NextBackOff()
+
+	b.Reset()
+	if delay := b.NextBackOff(); delay != 10*time.Millisecond {
+		t.Errorf("expected Reset to restart from InitialInterval, got %s", delay)
+	}
+}
+
+func TestExponentialBackoffJitterStaysWithinBounds(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval:     100 * time.Millisecond,
+		Multiplier:          1,
+		RandomizationFactor: 0.5,
+		MaxInterval:         time.Second,
+		MaxElapsedTime:      time.Second,
+	}
+	b.Reset()
+
+	for i := 0; i < 20; i++ {
+		delay := b.NextBackOff()
+		if delay < 50*time.Millisecond || delay > 150*time.Millisecond {
+			t.Errorf("expected jittered delay within [50ms, 150ms], got %s", delay)
+		}
+	}
+}
+
+func TestNewExponentialBackoffDefaults(t *testing.T) {
+	b := NewExponentialBackoff()
+	if b.InitialInterval != 100*time.Millisecond {
+		t.Errorf("expected default InitialInterval of 100ms, got %s", b.InitialInterval)
+	}
+	if b.MaxInterval != 2*time.Second {
+		t.Errorf("expected default MaxInterval of 2s, got %s", b.MaxInterval)
+	}
+	if b.MaxElapsedTime != 5*time.Second {
+		t.Errorf("expected default MaxElapsedTime of 5s, got %s", b.MaxElapsedTime)
+	}
+}
@@ -39,6 +39,57 @@ var (
 	ErrCircuitOpen = errors.New("circuit breaker is open")
 )
 
+// CallOutcome classifies how Execute recorded a single call, for the
+// OnCall hook registered via WithOnCall.
+type CallOutcome int
+
+const (
+	// CallOutcomeSuccess is fn returning nil.
+	CallOutcomeSuccess CallOutcome = iota
+	// CallOutcomeFailure is fn returning an error the failurePredicate
+	// counted (or fn panicking).
+	CallOutcomeFailure
+	// CallOutcomeIgnored is fn returning an error the failurePredicate
+	// rejected, so it didn't count as a failure or a success.
+	CallOutcomeIgnored
+	// CallOutcomeBlocked is Execute rejecting the call outright because
+	// the breaker was OPEN; fn never ran, so there is no duration.
+	CallOutcomeBlocked
+)
+
+func (o CallOutcome) String() string {
+	switch o {
+	case CallOutcomeSuccess:
+		return "success"
+	case CallOutcomeFailure:
+		return "failure"
+	case CallOutcomeIgnored:
+		return "ignored"
+	case CallOutcomeBlocked:
+		return "blocked"
+	default:
+		return "unknown"
+	}
+}
+
+// Metrics lets a caller observe a CircuitBreaker's behavior from outside -
+// state transitions for alerting, and request/result counts for dashboards -
+// without the breaker itself taking a dependency on any particular metrics
+// backend. See NewPrometheusMetrics for the default implementation.
+type Metrics interface {
+	OnStateChange(name string, from, to State)
+	OnRequest(name string, allowed bool)
+	OnResult(name string, err error)
+}
+
+// noopMetrics is the default Metrics used when none is supplied via
+// WithMetrics, so CircuitBreaker never needs a nil check at the call sites.
+type noopMetrics struct{}
+
+func (noopMetrics) OnStateChange(name string, from, to State) {}
+func (noopMetrics) OnRequest(name string, allowed bool)       {}
+func (noopMetrics) OnResult(name string, err error)           {}
+
 // CircuitBreaker implements the circuit breaker pattern
 type CircuitBreaker struct {
 	name            string
@@ -48,16 +99,190 @@ type CircuitBreaker struct {
 	lastFailureTime time.Time
 	state           State
 	mutex           sync.RWMutex
+
+	metrics Metrics
+
+	// halfOpenMaxProbes caps how many requests are let through at once while
+	// the breaker is HALF-OPEN; 0 means unlimited (every caller races past
+	// allowRequest, which is what re-opens the circuit on the very first
+	// failure when several callers arrive concurrently). halfOpenProbes
+	// tracks how many of those probes are currently in flight, and is
+	// decremented as each completes without closing or reopening the
+	// circuit, so a later probe can take its slot.
+	halfOpenMaxProbes int
+	halfOpenProbes    int
+
+	// halfOpenSuccessThreshold is how many HALF-OPEN probes must succeed,
+	// in a row with no intervening failure, before the circuit closes; 0
+	// means 1 (the original behavior: the very first successful probe
+	// closes it). halfOpenSuccesses counts progress toward that threshold
+	// and is reset on any HALF-OPEN failure or on closing.
+	halfOpenSuccessThreshold int
+	halfOpenSuccesses        int
+
+	// windowCfg and window are non-nil only for a breaker built with
+	// NewCircuitBreakerWithWindow, in which case they replace maxFailures/
+	// failureCount entirely: Execute routes to recordWindowResult instead
+	// of recordFailure/recordSuccess. Consecutive-failure mode (the
+	// default, via NewCircuitBreaker) leaves both nil.
+	windowCfg *WindowConfig
+	window    callWindow
+
+	// failurePredicate decides whether an error returned by Execute's fn
+	// counts toward opening the circuit. Errors it rejects are neither a
+	// failure nor a success - they're ignored outright, the same way an
+	// etcd client lets context.Canceled/DeadlineExceeded bypass retry
+	// logic instead of treating a caller's own cancellation as the
+	// downstream's fault. Defaults to defaultFailurePredicate.
+	failurePredicate func(error) bool
+
+	// onStateChange and onCall are additional hooks registered via
+	// WithOnStateChange/WithOnCall (or added later via AddOnStateChange/
+	// AddOnCall, e.g. by otelcb.Instrument). Unlike Metrics, which is a
+	// single replaceable backend, any number of these can be registered;
+	// they're always invoked outside cb.mutex so a hook that itself calls
+	// State() or FailureCount() can't deadlock.
+	onStateChange []func(name string, from, to State)
+	onCall        []func(name string, outcome CallOutcome, dur time.Duration)
+}
+
+// defaultFailurePredicate is the default failurePredicate: it excludes a
+// caller's own context cancellation/deadline from counting against the
+// circuit, since those reflect the caller giving up, not the downstream
+// failing.
+func defaultFailurePredicate(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// CircuitBreakerOption customizes a CircuitBreaker beyond its required
+// constructor arguments.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithMetrics overrides the default no-op Metrics, so every OnStateChange/
+// OnRequest/OnResult call is forwarded to m instead of discarded.
+func WithMetrics(m Metrics) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.metrics = m
+	}
+}
+
+// WithHalfOpenMaxProbes caps how many requests are allowed through while the
+// breaker is HALF-OPEN, instead of every caller racing past allowRequest at
+// once and re-opening the circuit on the first one that fails.
+func WithHalfOpenMaxProbes(max int) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.halfOpenMaxProbes = max
+	}
+}
+
+// WithHalfOpenSuccessThreshold requires n consecutive successful HALF-OPEN
+// probes before the breaker closes, instead of the default of one, so a
+// single lucky probe against a still-flaky downstream doesn't flip the
+// circuit back to CLOSED only to start failing again immediately.
+func WithHalfOpenSuccessThreshold(n int) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.halfOpenSuccessThreshold = n
+	}
+}
+
+// WithFailurePredicate overrides which errors returned by Execute's fn count
+// toward opening the circuit. An error for which pred returns false is
+// ignored entirely - neither a failure nor a success - instead of the
+// default behavior of counting every non-nil error (other than a caller's
+// own context cancellation/deadline, see defaultFailurePredicate) as a
+// failure.
+func WithFailurePredicate(pred func(error) bool) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.failurePredicate = pred
+	}
+}
+
+// WithOnStateChange registers fn to run on every state transition, in
+// addition to whatever Metrics is configured. Unlike WithMetrics, multiple
+// WithOnStateChange options stack instead of replacing each other.
+func WithOnStateChange(fn func(name string, from, to State)) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.onStateChange = append(cb.onStateChange, fn)
+	}
+}
+
+// WithOnCall registers fn to run after every Execute call with the outcome
+// it was recorded as and, unless the call was blocked, how long fn ran.
+// Multiple WithOnCall options stack instead of replacing each other.
+func WithOnCall(fn func(name string, outcome CallOutcome, dur time.Duration)) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.onCall = append(cb.onCall, fn)
+	}
+}
+
+// AddOnStateChange registers an additional state-change hook on a breaker
+// that's already constructed, e.g. instrumentation attached after the fact
+// by otelcb.Instrument. See WithOnStateChange.
+func (cb *CircuitBreaker) AddOnStateChange(fn func(name string, from, to State)) {
+	cb.mutex.Lock()
+	cb.onStateChange = append(cb.onStateChange, fn)
+	cb.mutex.Unlock()
+}
+
+// AddOnCall registers an additional call hook on a breaker that's already
+// constructed. See WithOnCall.
+func (cb *CircuitBreaker) AddOnCall(fn func(name string, outcome CallOutcome, dur time.Duration)) {
+	cb.mutex.Lock()
+	cb.onCall = append(cb.onCall, fn)
+	cb.mutex.Unlock()
+}
+
+// Name returns the breaker's name, as passed to NewCircuitBreaker or
+// NewCircuitBreakerWithWindow.
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// effectiveHalfOpenSuccessThreshold returns halfOpenSuccessThreshold, or 1
+// if it wasn't set via WithHalfOpenSuccessThreshold.
+func (cb *CircuitBreaker) effectiveHalfOpenSuccessThreshold() int {
+	if cb.halfOpenSuccessThreshold > 0 {
+		return cb.halfOpenSuccessThreshold
+	}
+	return 1
 }
 
 // NewCircuitBreaker creates a new circuit breaker with the given configuration
-func NewCircuitBreaker(name string, maxFailures int, timeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		name:        name,
-		maxFailures: maxFailures,
-		timeout:     timeout,
-		state:       StateClosed,
+func NewCircuitBreaker(name string, maxFailures int, timeout time.Duration, opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		name:             name,
+		maxFailures:      maxFailures,
+		timeout:          timeout,
+		state:            StateClosed,
+		metrics:          noopMetrics{},
+		failurePredicate: defaultFailurePredicate,
+	}
+	for _, opt := range opts {
+		opt(cb)
 	}
+	return cb
+}
+
+// NewCircuitBreakerWithWindow creates a circuit breaker that trips on a
+// failure (and optionally slow-call) rate computed over a rolling window of
+// recent calls, instead of NewCircuitBreaker's consecutive-failure count -
+// a single success no longer resets an otherwise-unhealthy window. See
+// WindowConfig for the knobs; HALF-OPEN/OPEN behavior (including
+// WithHalfOpenMaxProbes) is unchanged from NewCircuitBreaker.
+func NewCircuitBreakerWithWindow(name string, cfg WindowConfig, opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		name:             name,
+		timeout:          cfg.OpenTimeout,
+		state:            StateClosed,
+		metrics:          noopMetrics{},
+		windowCfg:        &cfg,
+		window:           newCallWindow(cfg),
+		failurePredicate: defaultFailurePredicate,
+	}
+	for _, opt := range opts {
+		opt(cb)
+	}
+	return cb
 }
 
 // Execute runs the given function with circuit breaker protection
@@ -73,6 +298,8 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 
 	// Check if we can proceed
 	if !cb.allowRequest() {
+		cb.metrics.OnRequest(cb.name, false)
+		cb.fireCall(CallOutcomeBlocked, 0)
 		span.SetAttributes(attribute.Bool("circuit_breaker.blocked", true))
 		span.SetStatus(codes.Error, "circuit breaker is open")
 		slog.Warn("Circuit breaker is open, rejecting request",
@@ -83,38 +310,130 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 		return ErrCircuitOpen
 	}
 
+	cb.metrics.OnRequest(cb.name, true)
 	span.SetAttributes(attribute.Bool("circuit_breaker.blocked", false))
 
-	// Execute the function
-	err := fn()
+	// Execute the function, recovering a panic just long enough to record it
+	// as a failure and update state before re-panicking - otherwise a goroutine
+	// that panics through Execute would leave the breaker's half-open probe
+	// slot (and, in consecutive mode, its failure count) permanently wrong.
+	start := time.Now()
+	var err error
+	var panicVal any
+	func() {
+		defer func() {
+			panicVal = recover()
+		}()
+		err = fn()
+	}()
+	elapsed := time.Since(start)
 
-	if err != nil {
-		cb.recordFailure()
-		span.SetAttributes(attribute.Int("circuit_breaker.failure_count", cb.getFailureCount()))
+	if panicVal != nil {
+		cb.recordOutcome(true, elapsed)
+		cb.fireCall(CallOutcomeFailure, elapsed)
+		span.SetStatus(codes.Error, "operation panicked")
+		panic(panicVal)
+	}
+
+	cb.metrics.OnResult(cb.name, err)
+
+	if err == nil {
+		cb.recordOutcome(false, elapsed)
+		cb.fireCall(CallOutcomeSuccess, elapsed)
+		span.SetStatus(codes.Ok, "operation succeeded")
+		return nil
+	}
+
+	if !cb.failurePredicate(err) {
+		// Doesn't count toward opening the circuit (e.g. the caller's own
+		// context cancellation) - leave state untouched, aside from freeing
+		// a half-open probe slot this call occupied.
+		cb.recordIgnored()
+		cb.fireCall(CallOutcomeIgnored, elapsed)
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "operation failed")
-		slog.Warn("Circuit breaker recorded failure",
-			"name", cb.name,
-			"error", err,
-			"failure_count", cb.getFailureCount(),
-			"state", cb.getState().String(),
-		)
+		span.SetStatus(codes.Error, "operation failed (ignored by failure predicate)")
 		return err
 	}
 
-	cb.recordSuccess()
-	span.SetStatus(codes.Ok, "operation succeeded")
-	return nil
+	cb.recordOutcome(true, elapsed)
+	cb.fireCall(CallOutcomeFailure, elapsed)
+	span.SetAttributes(attribute.Int("circuit_breaker.failure_count", cb.getFailureCount()))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "operation failed")
+	slog.Warn("Circuit breaker recorded failure",
+		"name", cb.name,
+		"error", err,
+		"failure_count", cb.getFailureCount(),
+		"state", cb.getState().String(),
+	)
+	return err
 }
 
-// allowRequest checks if a request should be allowed based on the circuit state
+// fireCall invokes every hook registered via WithOnCall/AddOnCall. Hooks run
+// outside cb.mutex - which is already unreferenced here, since recordOutcome/
+// recordIgnored have returned by this point - so a hook calling back into
+// State() or FailureCount() can't deadlock.
+func (cb *CircuitBreaker) fireCall(outcome CallOutcome, dur time.Duration) {
+	cb.mutex.RLock()
+	hooks := cb.onCall
+	cb.mutex.RUnlock()
+	for _, fn := range hooks {
+		fn(cb.name, outcome, dur)
+	}
+}
+
+// fireStateChange forwards a state transition to cb.metrics and every hook
+// registered via WithOnStateChange/AddOnStateChange. Callers must invoke it
+// only after releasing cb.mutex.
+func (cb *CircuitBreaker) fireStateChange(from, to State) {
+	cb.metrics.OnStateChange(cb.name, from, to)
+	cb.mutex.RLock()
+	hooks := cb.onStateChange
+	cb.mutex.RUnlock()
+	for _, fn := range hooks {
+		fn(cb.name, from, to)
+	}
+}
+
+// recordOutcome dispatches a call's pass/fail verdict to whichever
+// evaluation strategy this breaker was built with.
+func (cb *CircuitBreaker) recordOutcome(failed bool, elapsed time.Duration) {
+	if cb.windowCfg != nil {
+		cb.recordWindowResult(failed, elapsed)
+		return
+	}
+	if failed {
+		cb.recordFailure()
+	} else {
+		cb.recordSuccess()
+	}
+}
+
+// recordIgnored handles a call whose error the failurePredicate rejected:
+// it doesn't count as a failure or a success, but if it was a HALF-OPEN
+// probe its slot still needs freeing, the same as a probe that completed
+// normally.
+func (cb *CircuitBreaker) recordIgnored() {
+	cb.mutex.Lock()
+	if cb.state == StateHalfOpen {
+		cb.halfOpenProbes--
+	}
+	cb.mutex.Unlock()
+}
+
+// allowRequest checks if a request should be allowed based on the circuit
+// state. While HALF-OPEN it also enforces halfOpenMaxProbes, so a burst of
+// concurrent callers can't all race past it and re-open the circuit on the
+// first one that happens to fail.
 func (cb *CircuitBreaker) allowRequest() bool {
 	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
+	var from, to State
+	changed := false
 
+	var allowed bool
 	switch cb.state {
 	case StateClosed:
-		return true
+		allowed = true
 	case StateOpen:
 		// Check if timeout has elapsed
 		if time.Since(cb.lastFailureTime) > cb.timeout {
@@ -122,21 +441,43 @@ func (cb *CircuitBreaker) allowRequest() bool {
 				"name", cb.name,
 				"timeout_elapsed", time.Since(cb.lastFailureTime),
 			)
+			from, to, changed = cb.state, StateHalfOpen, true
 			cb.state = StateHalfOpen
-			return true
+			cb.halfOpenProbes = 0
+			cb.halfOpenSuccesses = 0
+			allowed = cb.admitHalfOpenProbeLocked()
+		} else {
+			allowed = false
 		}
-		return false
 	case StateHalfOpen:
-		return true
+		allowed = cb.admitHalfOpenProbeLocked()
 	default:
+		allowed = false
+	}
+	cb.mutex.Unlock()
+
+	if changed {
+		cb.fireStateChange(from, to)
+	}
+	return allowed
+}
+
+// admitHalfOpenProbeLocked decides whether one more HALF-OPEN probe can be
+// admitted, given halfOpenMaxProbes (0 means unlimited). Caller must hold
+// cb.mutex.
+func (cb *CircuitBreaker) admitHalfOpenProbeLocked() bool {
+	if cb.halfOpenMaxProbes > 0 && cb.halfOpenProbes >= cb.halfOpenMaxProbes {
 		return false
 	}
+	cb.halfOpenProbes++
+	return true
 }
 
 // recordFailure records a failure and potentially opens the circuit
 func (cb *CircuitBreaker) recordFailure() {
 	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
+	var from, to State
+	changed := false
 
 	cb.failureCount++
 	cb.lastFailureTime = time.Now()
@@ -146,32 +487,123 @@ func (cb *CircuitBreaker) recordFailure() {
 		slog.Warn("Failure in half-open state, reopening circuit",
 			"name", cb.name,
 		)
+		from, to, changed = cb.state, StateOpen, true
 		cb.state = StateOpen
-		return
-	}
-
-	if cb.failureCount >= cb.maxFailures {
+		cb.halfOpenProbes = 0
+		cb.halfOpenSuccesses = 0
+	} else if cb.failureCount >= cb.maxFailures {
 		slog.Warn("Circuit breaker opened due to max failures reached",
 			"name", cb.name,
 			"failure_count", cb.failureCount,
 			"max_failures", cb.maxFailures,
 		)
+		from, to, changed = cb.state, StateOpen, true
 		cb.state = StateOpen
 	}
+	cb.mutex.Unlock()
+
+	if changed {
+		cb.fireStateChange(from, to)
+	}
+}
+
+// recordWindowResult records one call's outcome into cb.window and
+// re-evaluates the breaker's state: a HALF-OPEN probe closes or reopens the
+// circuit exactly as in consecutive-failure mode, while CLOSED re-checks
+// the window's failure/slow rate against WindowConfig's thresholds (once
+// MinimumCalls has been reached) after every call. failed reflects Execute's
+// failurePredicate, not merely whether fn returned a non-nil error.
+func (cb *CircuitBreaker) recordWindowResult(failed bool, elapsed time.Duration) {
+	cb.mutex.Lock()
+	slow := cb.windowCfg.SlowCallDurationThreshold > 0 && elapsed > cb.windowCfg.SlowCallDurationThreshold
+	cb.window.record(failed, slow)
+
+	var from, to State
+	changed := false
+
+	switch cb.state {
+	case StateHalfOpen:
+		if failed {
+			from, to, changed = cb.state, StateOpen, true
+			cb.state = StateOpen
+			cb.lastFailureTime = time.Now()
+			cb.halfOpenProbes = 0
+			cb.halfOpenSuccesses = 0
+			cb.window.reset()
+		} else {
+			cb.halfOpenSuccesses++
+			if cb.halfOpenSuccesses >= cb.effectiveHalfOpenSuccessThreshold() {
+				from, to, changed = cb.state, StateClosed, true
+				cb.state = StateClosed
+				cb.halfOpenProbes = 0
+				cb.halfOpenSuccesses = 0
+				cb.window.reset()
+			} else {
+				// Still HALF-OPEN: free this probe's slot so another can be
+				// admitted toward the success threshold.
+				cb.halfOpenProbes--
+			}
+		}
+	case StateClosed:
+		total, failures, slowCalls := cb.window.snapshot()
+		if total >= cb.windowCfg.MinimumCalls {
+			failureRate := float64(failures) / float64(total)
+			slowRate := float64(slowCalls) / float64(total)
+			tripOnFailures := cb.windowCfg.FailureRateThreshold > 0 && failureRate >= cb.windowCfg.FailureRateThreshold
+			tripOnSlow := cb.windowCfg.SlowCallRateThreshold > 0 && slowRate >= cb.windowCfg.SlowCallRateThreshold
+			if tripOnFailures || tripOnSlow {
+				from, to, changed = cb.state, StateOpen, true
+				cb.state = StateOpen
+				cb.lastFailureTime = time.Now()
+				cb.window.reset()
+			}
+		}
+	}
+	cb.mutex.Unlock()
+
+	if changed {
+		slog.Warn("Circuit breaker state changed",
+			"name", cb.name,
+			"from", from.String(),
+			"to", to.String(),
+		)
+		cb.fireStateChange(from, to)
+	}
 }
 
 // recordSuccess records a successful call and potentially closes the circuit
 func (cb *CircuitBreaker) recordSuccess() {
 	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
+	var from, to State
+	changed := false
 
 	if cb.state == StateHalfOpen {
-		slog.Info("Circuit breaker closing after successful call in half-open state",
-			"name", cb.name,
-		)
-		cb.state = StateClosed
+		cb.halfOpenSuccesses++
+		if cb.halfOpenSuccesses >= cb.effectiveHalfOpenSuccessThreshold() {
+			slog.Info("Circuit breaker closing after enough successful calls in half-open state",
+				"name", cb.name,
+				"successes", cb.halfOpenSuccesses,
+			)
+			from, to, changed = cb.state, StateClosed, true
+			cb.state = StateClosed
+			cb.failureCount = 0
+			cb.halfOpenProbes = 0
+			cb.halfOpenSuccesses = 0
+		} else {
+			// Still HALF-OPEN: free this probe's slot so another can be
+			// admitted toward the success threshold.
+			cb.halfOpenProbes--
+		}
+	} else if cb.state == StateClosed {
+		// Consecutive-failure mode: any success while CLOSED starts the
+		// count over.
 		cb.failureCount = 0
 	}
+	cb.mutex.Unlock()
+
+	if changed {
+		cb.fireStateChange(from, to)
+	}
 }
 
 // getState returns the current state (thread-safe)
@@ -181,10 +613,18 @@ func (cb *CircuitBreaker) getState() State {
 	return cb.state
 }
 
-// getFailureCount returns the current failure count (thread-safe)
+// getFailureCount returns the current failure count (thread-safe). In
+// window mode this is the window's current failure count rather than
+// cb.failureCount, which window mode never touches; snapshot can itself
+// rotate a time window's buckets, so this takes the write lock rather than
+// RLock even though it only reads the result.
 func (cb *CircuitBreaker) getFailureCount() int {
-	cb.mutex.RLock()
-	defer cb.mutex.RUnlock()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if cb.window != nil {
+		_, failures, _ := cb.window.snapshot()
+		return failures
+	}
 	return cb.failureCount
 }
 
@@ -205,4 +645,28 @@ func (cb *CircuitBreaker) Reset() {
 	cb.state = StateClosed
 	cb.failureCount = 0
 	cb.lastFailureTime = time.Time{}
+	cb.halfOpenProbes = 0
+	cb.halfOpenSuccesses = 0
+	if cb.window != nil {
+		cb.window.reset()
+	}
+}
+
+// Metrics returns a snapshot of the current window's call count, failure
+// count/rate, and slow-call count/rate. It's only meaningful for a breaker
+// built with NewCircuitBreakerWithWindow; a consecutive-failure breaker
+// always returns the zero value.
+func (cb *CircuitBreaker) Metrics() WindowMetrics {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if cb.window == nil {
+		return WindowMetrics{}
+	}
+	total, failures, slow := cb.window.snapshot()
+	m := WindowMetrics{Calls: total, Failures: failures, SlowCalls: slow}
+	if total > 0 {
+		m.FailureRate = float64(failures) / float64(total)
+		m.SlowCallRate = float64(slow) / float64(total)
+	}
+	return m
 }
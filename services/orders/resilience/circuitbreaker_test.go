@@ -3,6 +3,7 @@ package resilience
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -245,6 +246,469 @@ func TestCircuitBreakerSuccessfulCallsDoNotIncrementFailures(t *testing.T) {
 	}
 }
 
+func TestCircuitBreakerLimitsConcurrentHalfOpenProbes(t *testing.T) {
+	cb := NewCircuitBreaker("test", 2, 50*time.Millisecond, WithHalfOpenMaxProbes(1))
+	ctx := context.Background()
+
+	testErr := errors.New("test error")
+	for i := 0; i < 2; i++ {
+		_ = cb.Execute(ctx, func() error {
+			return testErr
+		})
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// Two callers race in concurrently while HALF-OPEN; only one probe
+	// should be admitted, the other rejected with ErrCircuitOpen.
+	var wg sync.WaitGroup
+	results := make([]error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = cb.Execute(ctx, func() error {
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	admitted, rejected := 0, 0
+	for _, err := range results {
+		switch err {
+		case nil:
+			admitted++
+		case ErrCircuitOpen:
+			rejected++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if admitted != 1 || rejected != 1 {
+		t.Errorf("expected exactly 1 admitted and 1 rejected probe, got %d admitted, %d rejected", admitted, rejected)
+	}
+}
+
+func TestCircuitBreakerHalfOpenSuccessThresholdRejectsExtraProbes(t *testing.T) {
+	cb := NewCircuitBreaker("test", 2, 50*time.Millisecond,
+		WithHalfOpenMaxProbes(1), WithHalfOpenSuccessThreshold(2))
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	for i := 0; i < 2; i++ {
+		_ = cb.Execute(ctx, func() error { return testErr })
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	// Only one probe is admitted at a time (HalfOpenMaxProbes=1); a second,
+	// concurrent caller is rejected outright.
+	var wg sync.WaitGroup
+	results := make([]error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = cb.Execute(ctx, func() error {
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	admitted, rejected := 0, 0
+	for _, err := range results {
+		switch err {
+		case nil:
+			admitted++
+		case ErrCircuitOpen:
+			rejected++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if admitted != 1 || rejected != 1 {
+		t.Errorf("expected 1 admitted and 1 rejected probe, got %d admitted, %d rejected", admitted, rejected)
+	}
+	// One success against a threshold of 2 isn't enough to close yet.
+	if cb.State() != StateHalfOpen {
+		t.Errorf("expected still HALF-OPEN after 1 of 2 required successes, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerHalfOpenClosesAfterSuccessThresholdReached(t *testing.T) {
+	cb := NewCircuitBreaker("test", 2, 50*time.Millisecond, WithHalfOpenSuccessThreshold(2))
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	for i := 0; i < 2; i++ {
+		_ = cb.Execute(ctx, func() error { return testErr })
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	if err := cb.Execute(ctx, func() error { return nil }); err != nil {
+		t.Fatalf("expected first half-open probe to be admitted, got %v", err)
+	}
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("expected still HALF-OPEN after 1 of 2 required successes, got %s", cb.State())
+	}
+
+	if err := cb.Execute(ctx, func() error { return nil }); err != nil {
+		t.Fatalf("expected second half-open probe to be admitted, got %v", err)
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("expected CLOSED once the success threshold was reached, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailureResetsSuccessCount(t *testing.T) {
+	cb := NewCircuitBreaker("test", 2, 50*time.Millisecond, WithHalfOpenSuccessThreshold(2))
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	for i := 0; i < 2; i++ {
+		_ = cb.Execute(ctx, func() error { return testErr })
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	_ = cb.Execute(ctx, func() error { return nil }) // 1 of 2 successes
+	_ = cb.Execute(ctx, func() error { return testErr })
+	if cb.State() != StateOpen {
+		t.Fatalf("expected OPEN after a half-open failure, got %s", cb.State())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := cb.Execute(ctx, func() error { return nil }); err != nil {
+		t.Fatalf("expected half-open probe to be admitted, got %v", err)
+	}
+	// The earlier success must not have carried over - this is only 1 of a
+	// fresh 2 required.
+	if cb.State() != StateHalfOpen {
+		t.Errorf("expected still HALF-OPEN, with the success count reset by the prior failure, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerMetricsHooksCalled(t *testing.T) {
+	var stateChanges []State
+	var requests []bool
+	var results []error
+
+	cb := NewCircuitBreaker("test", 1, 30*time.Second, WithMetrics(fakeMetrics{
+		onStateChange: func(name string, from, to State) { stateChanges = append(stateChanges, to) },
+		onRequest:     func(name string, allowed bool) { requests = append(requests, allowed) },
+		onResult:      func(name string, err error) { results = append(results, err) },
+	}))
+	ctx := context.Background()
+
+	testErr := errors.New("test error")
+	_ = cb.Execute(ctx, func() error { return testErr })
+
+	if len(requests) != 1 || !requests[0] {
+		t.Errorf("expected one allowed request recorded, got %v", requests)
+	}
+	if len(results) != 1 || results[0] != testErr {
+		t.Errorf("expected the failure recorded, got %v", results)
+	}
+	if len(stateChanges) != 1 || stateChanges[0] != StateOpen {
+		t.Errorf("expected a single transition to OPEN, got %v", stateChanges)
+	}
+}
+
+type fakeMetrics struct {
+	onStateChange func(name string, from, to State)
+	onRequest     func(name string, allowed bool)
+	onResult      func(name string, err error)
+}
+
+func (f fakeMetrics) OnStateChange(name string, from, to State) { f.onStateChange(name, from, to) }
+func (f fakeMetrics) OnRequest(name string, allowed bool)       { f.onRequest(name, allowed) }
+func (f fakeMetrics) OnResult(name string, err error)           { f.onResult(name, err) }
+
+func TestCircuitBreakerWithWindowTripsOnFailureRate(t *testing.T) {
+	cb := NewCircuitBreakerWithWindow("test", WindowConfig{
+		Type:                 CountBasedWindow,
+		Size:                 10,
+		MinimumCalls:         4,
+		FailureRateThreshold: 0.5,
+		OpenTimeout:          30 * time.Second,
+	})
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	// 2 failures, 2 successes: a 50% rate, hitting the threshold right as
+	// MinimumCalls is reached. A single success in the middle must not
+	// reset anything, unlike NewCircuitBreaker's consecutive-failure mode.
+	_ = cb.Execute(ctx, func() error { return testErr })
+	_ = cb.Execute(ctx, func() error { return nil })
+	_ = cb.Execute(ctx, func() error { return testErr })
+	_ = cb.Execute(ctx, func() error { return nil })
+
+	if cb.State() != StateOpen {
+		t.Errorf("expected OPEN after a 50%% failure rate over %d calls, got %s", cb.Metrics().Calls, cb.State())
+	}
+}
+
+func TestCircuitBreakerWithWindowIgnoresBelowMinimumCalls(t *testing.T) {
+	cb := NewCircuitBreakerWithWindow("test", WindowConfig{
+		Type:                 CountBasedWindow,
+		Size:                 10,
+		MinimumCalls:         5,
+		FailureRateThreshold: 0.5,
+	})
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	_ = cb.Execute(ctx, func() error { return testErr })
+	_ = cb.Execute(ctx, func() error { return testErr })
+
+	if cb.State() != StateClosed {
+		t.Errorf("expected CLOSED below MinimumCalls even at 100%% failures, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerWithWindowTripsOnSlowCallRate(t *testing.T) {
+	cb := NewCircuitBreakerWithWindow("test", WindowConfig{
+		Type:                      CountBasedWindow,
+		Size:                      10,
+		MinimumCalls:              2,
+		SlowCallDurationThreshold: 10 * time.Millisecond,
+		SlowCallRateThreshold:     0.5,
+	})
+	ctx := context.Background()
+
+	_ = cb.Execute(ctx, func() error { time.Sleep(20 * time.Millisecond); return nil })
+	_ = cb.Execute(ctx, func() error { time.Sleep(20 * time.Millisecond); return nil })
+
+	if cb.State() != StateOpen {
+		t.Errorf("expected OPEN from slow-call rate even though every call succeeded, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerWithWindowHalfOpenProbeRecovers(t *testing.T) {
+	cb := NewCircuitBreakerWithWindow("test", WindowConfig{
+		Type:                 CountBasedWindow,
+		Size:                 10,
+		MinimumCalls:         1,
+		FailureRateThreshold: 0.5,
+		OpenTimeout:          50 * time.Millisecond,
+	})
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	_ = cb.Execute(ctx, func() error { return testErr })
+	if cb.State() != StateOpen {
+		t.Fatalf("expected OPEN, got %s", cb.State())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	_ = cb.Execute(ctx, func() error { return nil })
+	if cb.State() != StateClosed {
+		t.Errorf("expected CLOSED after a successful half-open probe, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerMetrics(t *testing.T) {
+	cb := NewCircuitBreakerWithWindow("test", WindowConfig{
+		Type:         CountBasedWindow,
+		Size:         10,
+		MinimumCalls: 100, // never trips; only the snapshot is under test
+	})
+	ctx := context.Background()
+	testErr := errors.New("test error")
+
+	_ = cb.Execute(ctx, func() error { return testErr })
+	_ = cb.Execute(ctx, func() error { return nil })
+	_ = cb.Execute(ctx, func() error { return nil })
+	_ = cb.Execute(ctx, func() error { return nil })
+
+	m := cb.Metrics()
+	if m.Calls != 4 || m.Failures != 1 || m.FailureRate != 0.25 {
+		t.Errorf("expected {Calls:4 Failures:1 FailureRate:0.25}, got %+v", m)
+	}
+}
+
+func TestCircuitBreakerDefaultPredicateIgnoresContextCancellation(t *testing.T) {
+	cb := NewCircuitBreaker("test", 2, 100*time.Millisecond)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		err := cb.Execute(ctx, func() error { return context.Canceled })
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected Execute to pass through context.Canceled, got %v", err)
+		}
+	}
+
+	if cb.State() != StateClosed {
+		t.Errorf("expected CLOSED after repeated context.Canceled, got %s", cb.State())
+	}
+	if cb.FailureCount() != 0 {
+		t.Errorf("expected failure count to stay 0 for ignored errors, got %d", cb.FailureCount())
+	}
+}
+
+func TestCircuitBreakerDefaultPredicateIgnoresWrappedDeadlineExceeded(t *testing.T) {
+	cb := NewCircuitBreaker("test", 1, 100*time.Millisecond)
+	ctx := context.Background()
+	wrapped := fmt.Errorf("calling downstream: %w", context.DeadlineExceeded)
+
+	_ = cb.Execute(ctx, func() error { return wrapped })
+	_ = cb.Execute(ctx, func() error { return wrapped })
+
+	if cb.State() != StateClosed {
+		t.Errorf("expected CLOSED for wrapped context.DeadlineExceeded, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerDefaultPredicateTripsOnOrdinaryError(t *testing.T) {
+	cb := NewCircuitBreaker("test", 1, 100*time.Millisecond)
+	ctx := context.Background()
+	testErr := errors.New("downstream unavailable")
+
+	_ = cb.Execute(ctx, func() error { return testErr })
+
+	if cb.State() != StateOpen {
+		t.Errorf("expected OPEN after an ordinary error, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerWithFailurePredicateOverride(t *testing.T) {
+	notFound := errors.New("not found")
+	cb := NewCircuitBreaker("test", 1, 100*time.Millisecond,
+		WithFailurePredicate(func(err error) bool { return !errors.Is(err, notFound) }),
+	)
+	ctx := context.Background()
+
+	_ = cb.Execute(ctx, func() error { return notFound })
+	if cb.State() != StateClosed {
+		t.Errorf("expected CLOSED for a predicate-excluded error, got %s", cb.State())
+	}
+
+	_ = cb.Execute(ctx, func() error { return errors.New("boom") })
+	if cb.State() != StateOpen {
+		t.Errorf("expected OPEN for a predicate-included error, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerPanicRecordsFailureAndRepanics(t *testing.T) {
+	cb := NewCircuitBreaker("test", 1, 100*time.Millisecond)
+	ctx := context.Background()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected Execute to re-panic")
+			}
+		}()
+		_ = cb.Execute(ctx, func() error { panic("downstream exploded") })
+	}()
+
+	if cb.State() != StateOpen {
+		t.Errorf("expected OPEN after a panic, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerPanicFreesHalfOpenProbeSlot(t *testing.T) {
+	cb := NewCircuitBreakerWithWindow("test", WindowConfig{
+		Type:                 CountBasedWindow,
+		Size:                 10,
+		MinimumCalls:         1,
+		FailureRateThreshold: 0.5,
+		OpenTimeout:          20 * time.Millisecond,
+	}, WithHalfOpenMaxProbes(1))
+	ctx := context.Background()
+
+	_ = cb.Execute(ctx, func() error { return errors.New("boom") })
+	if cb.State() != StateOpen {
+		t.Fatalf("expected OPEN, got %s", cb.State())
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	func() {
+		defer func() { recover() }()
+		_ = cb.Execute(ctx, func() error { panic("probe exploded") })
+	}()
+	if cb.State() != StateOpen {
+		t.Fatalf("expected a panicking probe to reopen the circuit, got %s", cb.State())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := cb.Execute(ctx, func() error { return nil }); err != nil {
+		t.Errorf("expected the next half-open probe to be admitted, got %v", err)
+	}
+}
+
+func TestCircuitBreakerOnStateChangeHookFires(t *testing.T) {
+	var got []string
+	cb := NewCircuitBreaker("test", 1, 30*time.Second,
+		WithOnStateChange(func(name string, from, to State) {
+			got = append(got, name+":"+from.String()+"->"+to.String())
+		}),
+	)
+	ctx := context.Background()
+
+	_ = cb.Execute(ctx, func() error { return errors.New("boom") })
+
+	want := "test:CLOSED->OPEN"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("expected [%q], got %v", want, got)
+	}
+}
+
+func TestCircuitBreakerOnCallHookFiresPerOutcome(t *testing.T) {
+	var outcomes []CallOutcome
+	cb := NewCircuitBreaker("test", 1, 30*time.Second,
+		WithOnCall(func(name string, outcome CallOutcome, dur time.Duration) {
+			outcomes = append(outcomes, outcome)
+		}),
+	)
+	ctx := context.Background()
+
+	_ = cb.Execute(ctx, func() error { return nil })
+	_ = cb.Execute(ctx, func() error { return errors.New("boom") })
+	_ = cb.Execute(ctx, func() error { return nil })
+
+	want := []CallOutcome{CallOutcomeSuccess, CallOutcomeFailure, CallOutcomeBlocked}
+	if len(outcomes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, outcomes)
+	}
+	for i, o := range want {
+		if outcomes[i] != o {
+			t.Errorf("outcome %d: expected %s, got %s", i, o, outcomes[i])
+		}
+	}
+}
+
+func TestCircuitBreakerAddOnStateChangeAfterConstruction(t *testing.T) {
+	cb := NewCircuitBreaker("test", 1, 30*time.Second)
+	var fired bool
+	cb.AddOnStateChange(func(name string, from, to State) { fired = true })
+
+	_ = cb.Execute(context.Background(), func() error { return errors.New("boom") })
+
+	if !fired {
+		t.Error("expected a hook added via AddOnStateChange after construction to fire")
+	}
+}
+
+func TestRegistryTracksConstructedBreakers(t *testing.T) {
+	reg := NewRegistry()
+	cb := reg.NewCircuitBreaker("inventory", 3, 30*time.Second)
+	_ = reg.NewCircuitBreakerWithWindow("payment", WindowConfig{Type: CountBasedWindow, Size: 10})
+
+	if got := reg.Get("inventory"); got != cb {
+		t.Errorf("expected Get to return the breaker constructed under that name")
+	}
+	if reg.Get("missing") != nil {
+		t.Error("expected Get of an unregistered name to return nil")
+	}
+	if len(reg.All()) != 2 {
+		t.Errorf("expected All to return 2 breakers, got %d", len(reg.All()))
+	}
+}
+
 func TestStateString(t *testing.T) {
 	tests := []struct {
 		state    State
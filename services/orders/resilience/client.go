@@ -0,0 +1,124 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Client wraps net/http with a per-endpoint circuit breaker and jittered
+// exponential backoff retry, so a call site talking to a peer service fails
+// fast once that peer is unhealthy instead of piling up retrying goroutines.
+// It's shared across the orders service's outbound HTTP calls (inventory,
+// payment, ...) rather than each call site reimplementing its own loop.
+type Client struct {
+	Breaker    *CircuitBreaker
+	httpClient *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// NewClient creates a resilient HTTP client for a single named endpoint,
+// exporting its breaker's state/requests/failures via Prometheus so an
+// unhealthy downstream is visible without digging through traces.
+func NewClient(name string, requestTimeout time.Duration, cbMaxFailures int, cbTimeout time.Duration) *Client {
+	return &Client{
+		Breaker:    NewCircuitBreaker(name, cbMaxFailures, cbTimeout, WithMetrics(NewPrometheusMetrics())),
+		httpClient: &http.Client{Timeout: requestTimeout},
+		maxRetries: 3,
+		baseDelay:  100 * time.Millisecond,
+	}
+}
+
+// ShouldRetry decides, from a completed response, whether the caller wants
+// another attempt. It isn't called on a transport error (err != nil); those
+// are always eligible for retry.
+type ShouldRetry func(resp *http.Response) bool
+
+// RetryOn5xx is the ShouldRetry most callers want: retry server errors, treat
+// everything else (2xx/4xx) as final.
+func RetryOn5xx(resp *http.Response) bool {
+	return resp.StatusCode >= 500
+}
+
+// Do executes newReq (called fresh on every attempt, since a request's body
+// can't be replayed once read) under circuit breaker protection with
+// jittered exponential backoff retry for transport errors and responses
+// shouldRetry accepts. The caller owns resp.Body on a non-error return.
+func (c *Client) Do(ctx context.Context, name string, newReq func(ctx context.Context) (*http.Request, error), shouldRetry ShouldRetry) (*http.Response, error) {
+	tracer := otel.Tracer("orders-service")
+	ctx, span := tracer.Start(ctx, name+".Do")
+	defer span.End()
+
+	var resp *http.Response
+	err := c.Breaker.Execute(ctx, func() error {
+		var innerErr error
+		resp, innerErr = c.doWithRetry(ctx, newReq, shouldRetry, span)
+		return innerErr
+	})
+
+	span.SetAttributes(attribute.String("circuit.state", c.Breaker.State().String()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) doWithRetry(ctx context.Context, newReq func(ctx context.Context) (*http.Request, error), shouldRetry ShouldRetry, span trace.Span) (*http.Response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		req, err := newReq(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err == nil && !shouldRetry(resp) {
+			span.SetAttributes(attribute.Int("retry.count", attempt))
+			return resp, nil
+		}
+
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("retryable response: status %d", resp.StatusCode)
+			resp.Body.Close()
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if attempt < c.maxRetries {
+			select {
+			case <-time.After(jitteredDelay(c.baseDelay, attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Int("retry.count", c.maxRetries+1))
+	return nil, fmt.Errorf("request failed after %d attempts: %w", c.maxRetries+1, lastErr)
+}
+
+// jitteredDelay computes a full-jitter exponential backoff: a random value
+// between 0 and base*2^attempt, which avoids synchronized retry storms
+// across many concurrent callers hitting the same failing dependency.
+func jitteredDelay(base time.Duration, attempt int) time.Duration {
+	ceiling := base * time.Duration(1<<uint(attempt))
+	if ceiling <= 0 {
+		return base
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
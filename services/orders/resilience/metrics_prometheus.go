@@ -0,0 +1,71 @@
+package resilience
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// circuitBreakerState exports each named breaker's current state as a
+// gauge (0=CLOSED, 1=OPEN, 2=HALF-OPEN) so it can be graphed and alerted on
+// directly, rather than inferred from request/failure counters.
+var circuitBreakerState = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "circuit_breaker_state",
+		Help: "Current circuit breaker state by name (0=CLOSED, 1=OPEN, 2=HALF-OPEN).",
+	},
+	[]string{"name"},
+)
+
+// circuitBreakerRequestsTotal counts every Execute call, labeled by whether
+// it was let through or rejected while the breaker was open.
+var circuitBreakerRequestsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "circuit_breaker_requests_total",
+		Help: "Total circuit breaker requests by name and outcome (allowed/rejected).",
+	},
+	[]string{"name", "outcome"},
+)
+
+// circuitBreakerFailuresTotal counts failed calls that made it through the
+// breaker, independent of whether that failure tripped a state change.
+var circuitBreakerFailuresTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "circuit_breaker_failures_total",
+		Help: "Total failures recorded by a circuit breaker, by name.",
+	},
+	[]string{"name"},
+)
+
+func init() {
+	prometheus.MustRegister(circuitBreakerState, circuitBreakerRequestsTotal, circuitBreakerFailuresTotal)
+}
+
+// PrometheusMetrics is the default Metrics implementation, exporting
+// circuit_breaker_state/circuit_breaker_requests_total/
+// circuit_breaker_failures_total so an unhealthy dependency (e.g.
+// inventory-service flipping to OPEN) is visible and alertable without
+// reading logs or traces.
+type PrometheusMetrics struct{}
+
+// NewPrometheusMetrics returns the shared Prometheus-backed Metrics
+// implementation. It's stateless, so every CircuitBreaker can use the same
+// value - the metric vectors themselves, registered once in init, carry
+// the per-breaker state via their "name" label.
+func NewPrometheusMetrics() PrometheusMetrics {
+	return PrometheusMetrics{}
+}
+
+func (PrometheusMetrics) OnStateChange(name string, from, to State) {
+	circuitBreakerState.WithLabelValues(name).Set(float64(to))
+}
+
+func (PrometheusMetrics) OnRequest(name string, allowed bool) {
+	outcome := "allowed"
+	if !allowed {
+		outcome = "rejected"
+	}
+	circuitBreakerRequestsTotal.WithLabelValues(name, outcome).Inc()
+}
+
+func (PrometheusMetrics) OnResult(name string, err error) {
+	if err != nil {
+		circuitBreakerFailuresTotal.WithLabelValues(name).Inc()
+	}
+}
@@ -0,0 +1,84 @@
+// Package otelcb wires a resilience.Registry's circuit breakers into
+// OpenTelemetry: a call counter and duration histogram per breaker and
+// outcome, an up-down counter tracking each breaker's current state, and a
+// span event on every state transition.
+package otelcb
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafidoth/orders-service/resilience"
+)
+
+const instrumentationName = "orders-service/resilience"
+
+// Instrument attaches OpenTelemetry metrics and tracing to every breaker
+// currently in reg via WithOnCall/WithOnStateChange-equivalent hooks. It's
+// meant to run once, after the app has finished constructing its breakers
+// through reg - a breaker reg.NewCircuitBreaker's after this call isn't
+// covered.
+func Instrument(reg *resilience.Registry) error {
+	meter := otel.Meter(instrumentationName)
+	tracer := otel.Tracer(instrumentationName)
+
+	calls, err := meter.Int64Counter("circuitbreaker.calls",
+		metric.WithDescription("Circuit breaker calls by outcome"),
+	)
+	if err != nil {
+		return err
+	}
+	duration, err := meter.Float64Histogram("circuitbreaker.call.duration",
+		metric.WithDescription("Circuit breaker call duration"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+	state, err := meter.Int64UpDownCounter("circuitbreaker.state",
+		metric.WithDescription("Circuit breaker state (0=closed, 1=open, 2=half-open)"),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, cb := range reg.All() {
+		// Seed the gauge-like up-down counter with the breaker's current
+		// value; every subsequent state change below only applies the delta.
+		state.Add(context.Background(), int64(cb.State()), metric.WithAttributes(
+			attribute.String("name", cb.Name()),
+		))
+
+		cb.AddOnCall(func(name string, outcome resilience.CallOutcome, dur time.Duration) {
+			attrs := metric.WithAttributes(
+				attribute.String("name", name),
+				attribute.String("outcome", outcome.String()),
+			)
+			calls.Add(context.Background(), 1, attrs)
+			if outcome != resilience.CallOutcomeBlocked {
+				duration.Record(context.Background(), dur.Seconds(), attrs)
+			}
+		})
+
+		cb.AddOnStateChange(func(name string, from, to resilience.State) {
+			state.Add(context.Background(), int64(to)-int64(from), metric.WithAttributes(
+				attribute.String("name", name),
+			))
+
+			_, span := tracer.Start(context.Background(), "circuitbreaker.state_change")
+			span.AddEvent("circuit_breaker.transition", trace.WithAttributes(
+				attribute.String("circuit_breaker.name", name),
+				attribute.String("circuit_breaker.from", from.String()),
+				attribute.String("circuit_breaker.to", to.String()),
+			))
+			span.End()
+		})
+	}
+
+	return nil
+}
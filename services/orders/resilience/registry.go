@@ -0,0 +1,66 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+// Registry tracks every CircuitBreaker constructed through it, keyed by
+// name, so instrumentation (see otelcb.Instrument) can attach itself to all
+// of them with a single call instead of every call site wiring its own
+// hooks. Using a Registry is optional - NewCircuitBreaker/
+// NewCircuitBreakerWithWindow still work unregistered - but app code that
+// wants process-wide instrumentation should construct its breakers through
+// one.
+type Registry struct {
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewRegistry returns an empty Registry ready to have breakers constructed
+// through it.
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*CircuitBreaker)}
+}
+
+// NewCircuitBreaker builds a consecutive-failure breaker exactly like the
+// package-level NewCircuitBreaker, and additionally registers it under
+// name.
+func (r *Registry) NewCircuitBreaker(name string, maxFailures int, timeout time.Duration, opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := NewCircuitBreaker(name, maxFailures, timeout, opts...)
+	r.add(cb)
+	return cb
+}
+
+// NewCircuitBreakerWithWindow builds a window-mode breaker exactly like the
+// package-level NewCircuitBreakerWithWindow, and additionally registers it
+// under name.
+func (r *Registry) NewCircuitBreakerWithWindow(name string, cfg WindowConfig, opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := NewCircuitBreakerWithWindow(name, cfg, opts...)
+	r.add(cb)
+	return cb
+}
+
+func (r *Registry) add(cb *CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakers[cb.name] = cb
+}
+
+// All returns every breaker currently registered, in no particular order.
+func (r *Registry) All() []*CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		out = append(out, cb)
+	}
+	return out
+}
+
+// Get returns the breaker registered under name, or nil if none was.
+func (r *Registry) Get(name string) *CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.breakers[name]
+}
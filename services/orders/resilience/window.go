@@ -0,0 +1,232 @@
+package resilience
+
+import "time"
+
+// WindowType selects how NewCircuitBreakerWithWindow retains recent call
+// outcomes for its failure-rate calculation.
+type WindowType int
+
+const (
+	// CountBasedWindow retains the last Size calls, regardless of how long
+	// they took to arrive.
+	CountBasedWindow WindowType = iota
+	// TimeBasedWindow retains every call from the last Duration, bucketed
+	// internally so old calls age out without a per-call scan.
+	TimeBasedWindow
+)
+
+// WindowConfig configures NewCircuitBreakerWithWindow's failure-rate
+// evaluation, used in place of NewCircuitBreaker's consecutive-failure
+// count.
+type WindowConfig struct {
+	// Type selects CountBasedWindow (the default, Size) or TimeBasedWindow
+	// (Duration/Buckets).
+	Type WindowType
+
+	// Size is the number of most recent calls retained when Type is
+	// CountBasedWindow.
+	Size int
+
+	// Duration is the span of time retained when Type is TimeBasedWindow.
+	Duration time.Duration
+	// Buckets is how many buckets Duration is divided into for rotation;
+	// defaults to 10 if unset.
+	Buckets int
+
+	// FailureRateThreshold trips the circuit once the window's failure
+	// rate (failures / calls) reaches it. Zero disables the failure-rate
+	// check entirely, which only makes sense paired with a non-zero
+	// SlowCallRateThreshold.
+	FailureRateThreshold float64
+
+	// SlowCallDurationThreshold marks a call "slow" once it runs longer
+	// than this. Zero disables slow-call tracking.
+	SlowCallDurationThreshold time.Duration
+	// SlowCallRateThreshold trips the circuit once the window's slow-call
+	// rate (slow / calls) reaches it. Zero disables the slow-rate check.
+	SlowCallRateThreshold float64
+
+	// MinimumCalls gates evaluation: the window's rates aren't checked
+	// until at least this many calls have landed, so a handful of early
+	// failures can't trip the circuit by themselves.
+	MinimumCalls int
+
+	// OpenTimeout is how long the breaker stays OPEN before admitting a
+	// HALF-OPEN probe - the same role NewCircuitBreaker's timeout
+	// parameter plays.
+	OpenTimeout time.Duration
+}
+
+// WindowMetrics is a point-in-time snapshot of a window-mode
+// CircuitBreaker's recent call outcomes, as returned by Metrics().
+type WindowMetrics struct {
+	Calls        int
+	Failures     int
+	SlowCalls    int
+	FailureRate  float64
+	SlowCallRate float64
+}
+
+// callWindow is the rolling store a window-mode CircuitBreaker records
+// outcomes into; countWindow and timeWindow are its two implementations.
+// Every method is called with cb.mutex already held for writing, since
+// snapshot can itself mutate (a time window lazily rotates its buckets).
+type callWindow interface {
+	record(failed, slow bool)
+	snapshot() (total, failures, slow int)
+	reset()
+}
+
+// countWindow is a fixed-size ring buffer of the outcomes of the last N
+// calls, with O(1) record/snapshot by keeping running failure/slow totals
+// alongside the buffer instead of rescanning it.
+type countWindow struct {
+	outcomes []callOutcome
+	pos      int
+	filled   bool
+	failures int
+	slow     int
+}
+
+type callOutcome struct {
+	failed bool
+	slow   bool
+}
+
+func newCountWindow(size int) *countWindow {
+	if size <= 0 {
+		size = 1
+	}
+	return &countWindow{outcomes: make([]callOutcome, size)}
+}
+
+func (w *countWindow) record(failed, slow bool) {
+	if w.filled {
+		old := w.outcomes[w.pos]
+		if old.failed {
+			w.failures--
+		}
+		if old.slow {
+			w.slow--
+		}
+	}
+
+	w.outcomes[w.pos] = callOutcome{failed: failed, slow: slow}
+	if failed {
+		w.failures++
+	}
+	if slow {
+		w.slow++
+	}
+
+	w.pos++
+	if w.pos == len(w.outcomes) {
+		w.pos = 0
+		w.filled = true
+	}
+}
+
+func (w *countWindow) snapshot() (total, failures, slow int) {
+	total = w.pos
+	if w.filled {
+		total = len(w.outcomes)
+	}
+	return total, w.failures, w.slow
+}
+
+func (w *countWindow) reset() {
+	for i := range w.outcomes {
+		w.outcomes[i] = callOutcome{}
+	}
+	w.pos, w.filled, w.failures, w.slow = 0, false, 0, 0
+}
+
+// timeWindow retains calls from the last Duration via a ring of buckets
+// rotated lazily (on record/snapshot) rather than by a background ticker,
+// so an idle breaker costs nothing between calls.
+type timeWindow struct {
+	bucketDuration time.Duration
+	buckets        []bucketCounts
+	currentIdx     int
+	currentStart   time.Time
+}
+
+type bucketCounts struct {
+	total    int
+	failures int
+	slow     int
+}
+
+func newTimeWindow(duration time.Duration, numBuckets int) *timeWindow {
+	if numBuckets <= 0 {
+		numBuckets = 10
+	}
+	if duration <= 0 {
+		duration = time.Minute
+	}
+	return &timeWindow{
+		bucketDuration: duration / time.Duration(numBuckets),
+		buckets:        make([]bucketCounts, numBuckets),
+		currentStart:   time.Now(),
+	}
+}
+
+// advance rotates the ring forward to now, clearing buckets that have aged
+// out of the window. A gap longer than the whole window just clears
+// everything instead of stepping through it one bucket at a time.
+func (w *timeWindow) advance(now time.Time) {
+	steps := int(now.Sub(w.currentStart) / w.bucketDuration)
+	if steps <= 0 {
+		return
+	}
+	if steps >= len(w.buckets) {
+		for i := range w.buckets {
+			w.buckets[i] = bucketCounts{}
+		}
+		w.currentIdx = 0
+		w.currentStart = now
+		return
+	}
+	for i := 0; i < steps; i++ {
+		w.currentIdx = (w.currentIdx + 1) % len(w.buckets)
+		w.buckets[w.currentIdx] = bucketCounts{}
+	}
+	w.currentStart = w.currentStart.Add(time.Duration(steps) * w.bucketDuration)
+}
+
+func (w *timeWindow) record(failed, slow bool) {
+	w.advance(time.Now())
+	b := &w.buckets[w.currentIdx]
+	b.total++
+	if failed {
+		b.failures++
+	}
+	if slow {
+		b.slow++
+	}
+}
+
+func (w *timeWindow) snapshot() (total, failures, slow int) {
+	w.advance(time.Now())
+	for _, b := range w.buckets {
+		total += b.total
+		failures += b.failures
+		slow += b.slow
+	}
+	return total, failures, slow
+}
+
+func (w *timeWindow) reset() {
+	for i := range w.buckets {
+		w.buckets[i] = bucketCounts{}
+	}
+	w.currentIdx = 0
+	w.currentStart = time.Now()
+}
+
+func newCallWindow(cfg WindowConfig) callWindow {
+	if cfg.Type == TimeBasedWindow {
+		return newTimeWindow(cfg.Duration, cfg.Buckets)
+	}
+	return newCountWindow(cfg.Size)
+}
@@ -0,0 +1,70 @@
+package saga
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// AdminHandler exposes read-only saga inspection endpoints for operators,
+// separate from the coordinator's own API so it can be mounted only behind
+// an internal/admin route.
+type AdminHandler struct {
+	coordinator *Coordinator
+}
+
+// NewAdminHandler wraps a Coordinator for the admin HTTP surface.
+func NewAdminHandler(coordinator *Coordinator) *AdminHandler {
+	return &AdminHandler{coordinator: coordinator}
+}
+
+type stuckSagaResponse struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	Status    string         `json:"status"`
+	LastError string         `json:"last_error,omitempty"`
+	UpdatedAt string         `json:"updated_at"`
+	Steps     []stepResponse `json:"steps"`
+}
+
+type stepResponse struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// ListStuck returns every saga that needs manual attention: one whose
+// compensation itself failed, or one still running with no builder
+// registered to resume it after a restart.
+func (h *AdminHandler) ListStuck(w http.ResponseWriter, r *http.Request) {
+	stuck, err := h.coordinator.ListStuck(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to list stuck sagas", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]stuckSagaResponse, 0, len(stuck))
+	for _, s := range stuck {
+		steps := make([]stepResponse, 0, len(s.Steps))
+		for _, step := range s.Steps {
+			steps = append(steps, stepResponse{
+				Name:      step.Name,
+				Status:    string(step.Status),
+				Attempts:  step.Attempts,
+				LastError: step.LastError,
+			})
+		}
+		response = append(response, stuckSagaResponse{
+			ID:        s.ID,
+			Type:      s.Type,
+			Status:    string(s.Status),
+			LastError: s.LastError,
+			UpdatedAt: s.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Steps:     steps,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,403 @@
+// Package saga implements a small compensating-transaction coordinator: a
+// saga is an ordered list of steps, each with a Run and a Compensate, run in
+// order until one fails, at which point every already-completed step is
+// compensated in reverse. Progress is persisted to Postgres so an
+// interrupted saga (process restart mid-run) can be resumed rather than
+// left half-done.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Status is a saga's overall lifecycle state.
+type Status string
+
+const (
+	StatusRunning     Status = "RUNNING"
+	StatusCompleted   Status = "COMPLETED"
+	StatusCompensated Status = "COMPENSATED"
+	StatusStuck       Status = "STUCK"
+)
+
+// StepStatus is a single step's lifecycle state within a saga.
+type StepStatus string
+
+const (
+	StepPending     StepStatus = "PENDING"
+	StepCompleted   StepStatus = "COMPLETED"
+	StepFailed      StepStatus = "FAILED"
+	StepCompensated StepStatus = "COMPENSATED"
+)
+
+// Step is one unit of work in a saga. Run and Compensate are rebuilt fresh
+// for every attempt (they're closures over live client/db references, not
+// something we try to serialize), so a resumed saga calls a Builder to get
+// a fresh []Step rather than reloading the original closures from storage.
+type Step struct {
+	Name       string
+	Run        func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+}
+
+// Builder reconstructs the steps for a saga of a given type from its
+// persisted payload, so ResumeAll can replay an interrupted saga without
+// the process that started it still being alive. Only needed for saga
+// types registered via Coordinator.Register; Start itself takes live steps
+// directly.
+type Builder func(ctx context.Context, payload json.RawMessage) ([]Step, error)
+
+// Coordinator runs sagas and persists their progress to Postgres.
+type Coordinator struct {
+	db       *pgxpool.Pool
+	builders map[string]Builder
+}
+
+// NewCoordinator creates a saga coordinator backed by conn. Register any
+// Builders before calling ResumeAll.
+func NewCoordinator(conn *pgxpool.Pool) *Coordinator {
+	return &Coordinator{db: conn, builders: make(map[string]Builder)}
+}
+
+// Register associates a saga type with the Builder that can reconstruct its
+// steps from a persisted payload, for use by ResumeAll.
+func (c *Coordinator) Register(sagaType string, builder Builder) {
+	c.builders[sagaType] = builder
+}
+
+// Start persists a new saga row, then runs steps in order. It returns the
+// saga's id and the *original, unwrapped* error a failed step returned (not
+// a saga-specific wrapper), so existing errors.Is/errors.As call sites at
+// the caller keep working unchanged regardless of whether the call went
+// through a saga.
+func (c *Coordinator) Start(ctx context.Context, sagaType string, payload any, steps []Step) (string, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("saga: marshal payload: %w", err)
+	}
+
+	var sagaID string
+	err = c.db.QueryRow(ctx,
+		`INSERT INTO sagas (saga_type, payload, status) VALUES ($1, $2, $3) RETURNING id`,
+		sagaType, payloadJSON, StatusRunning,
+	).Scan(&sagaID)
+	if err != nil {
+		return "", fmt.Errorf("saga: create saga: %w", err)
+	}
+
+	for i, step := range steps {
+		if _, err := c.db.Exec(ctx,
+			`INSERT INTO saga_steps (saga_id, step_index, name, status) VALUES ($1, $2, $3, $4)`,
+			sagaID, i, step.Name, StepPending,
+		); err != nil {
+			slog.Error("saga: failed to record step", "saga_id", sagaID, "step", step.Name, "error", err)
+		}
+	}
+
+	runErr := c.run(ctx, sagaID, sagaType, steps, 0)
+	return sagaID, runErr
+}
+
+// run executes steps[from:] in order, compensating everything completed so
+// far (in reverse) the moment one fails, and records every transition along
+// the way so a crash mid-run leaves an accurate trail for ResumeAll.
+func (c *Coordinator) run(ctx context.Context, sagaID, sagaType string, steps []Step, from int) error {
+	tracer := otel.Tracer("orders-service")
+	ctx, span := tracer.Start(ctx, "Saga.Run")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("saga.id", sagaID),
+		attribute.String("saga.type", sagaType),
+	)
+
+	completed := make([]Step, 0, len(steps))
+	completed = append(completed, steps[:from]...)
+
+	for i := from; i < len(steps); i++ {
+		step := steps[i]
+		if err := c.runStep(ctx, sagaID, i, step); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, fmt.Sprintf("step %s failed", step.Name))
+			c.setSagaStatus(ctx, sagaID, StatusRunning, err)
+
+			if !c.compensate(ctx, sagaID, completed) {
+				c.setSagaStatus(ctx, sagaID, StatusCompensated, err)
+			}
+			return err
+		}
+		completed = append(completed, step)
+	}
+
+	span.SetStatus(codes.Ok, "saga completed")
+	c.setSagaStatus(ctx, sagaID, StatusCompleted, nil)
+	return nil
+}
+
+// runStep runs a single step, recording its attempt count and outcome.
+func (c *Coordinator) runStep(ctx context.Context, sagaID string, index int, step Step) error {
+	tracer := otel.Tracer("orders-service")
+	ctx, span := tracer.Start(ctx, "Saga.Step."+step.Name)
+	defer span.End()
+
+	c.incrementAttempts(ctx, sagaID, index)
+
+	if err := step.Run(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.setStepStatus(ctx, sagaID, index, StepFailed, err)
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "step completed")
+	c.setStepStatus(ctx, sagaID, index, StepCompleted, nil)
+	return nil
+}
+
+// compensate runs Compensate for every given step in reverse order,
+// logging (rather than aborting on) a compensation failure, since stopping
+// partway through would leave even more of the saga's side effects
+// unreversed than a single bad compensation already does. It reports whether
+// any compensation failed, in which case the saga has been marked STUCK.
+func (c *Coordinator) compensate(ctx context.Context, sagaID string, completed []Step) bool {
+	tracer := otel.Tracer("orders-service")
+	stuck := false
+
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		_, span := tracer.Start(ctx, "Saga.Compensate."+step.Name)
+
+		if step.Compensate == nil {
+			span.End()
+			continue
+		}
+
+		if err := step.Compensate(ctx); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			slog.Error("saga: compensation failed, saga left stuck for manual recovery",
+				"saga_id", sagaID, "step", step.Name, "error", err)
+			c.setStepStatus(ctx, sagaID, i, StepFailed, err)
+			c.setSagaStatus(ctx, sagaID, StatusStuck, err)
+			stuck = true
+			span.End()
+			continue
+		}
+
+		span.SetStatus(codes.Ok, "compensated")
+		c.setStepStatus(ctx, sagaID, i, StepCompensated, nil)
+		span.End()
+	}
+	return stuck
+}
+
+// ResumeAll replays every saga left RUNNING from a previous process (i.e.
+// the process died mid-saga), using the Builder registered for its type to
+// reconstruct live steps from the persisted payload. A saga whose type has
+// no registered Builder is left RUNNING and logged, rather than guessed at -
+// it'll show up via ListStuck for an operator to handle by hand.
+func (c *Coordinator) ResumeAll(ctx context.Context) error {
+	rows, err := c.db.Query(ctx, `SELECT id, saga_type, payload FROM sagas WHERE status = $1`, StatusRunning)
+	if err != nil {
+		return fmt.Errorf("saga: list running sagas: %w", err)
+	}
+	type pending struct {
+		id       string
+		sagaType string
+		payload  json.RawMessage
+	}
+	var toResume []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.sagaType, &p.payload); err != nil {
+			rows.Close()
+			return fmt.Errorf("saga: scan running saga: %w", err)
+		}
+		toResume = append(toResume, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("saga: iterate running sagas: %w", err)
+	}
+
+	for _, p := range toResume {
+		builder, ok := c.builders[p.sagaType]
+		if !ok {
+			slog.Warn("saga: no builder registered for saga type, leaving for manual recovery",
+				"saga_id", p.id, "saga_type", p.sagaType)
+			continue
+		}
+
+		steps, err := builder(ctx, p.payload)
+		if err != nil {
+			slog.Error("saga: failed to rebuild steps for resume", "saga_id", p.id, "saga_type", p.sagaType, "error", err)
+			continue
+		}
+
+		from, err := c.firstIncompleteStep(ctx, p.id, len(steps))
+		if err != nil {
+			slog.Error("saga: failed to determine resume point", "saga_id", p.id, "error", err)
+			continue
+		}
+
+		slog.Info("saga: resuming interrupted saga", "saga_id", p.id, "saga_type", p.sagaType, "from_step", from)
+		if err := c.run(ctx, p.id, p.sagaType, steps, from); err != nil {
+			slog.Warn("saga: resumed saga ended in compensation", "saga_id", p.id, "error", err)
+		}
+	}
+	return nil
+}
+
+// firstIncompleteStep returns the index of the first step not already
+// COMPLETED, so a resume replays from there instead of from scratch.
+func (c *Coordinator) firstIncompleteStep(ctx context.Context, sagaID string, total int) (int, error) {
+	rows, err := c.db.Query(ctx,
+		`SELECT step_index, status FROM saga_steps WHERE saga_id = $1 ORDER BY step_index`, sagaID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	completedThrough := -1
+	for rows.Next() {
+		var index int
+		var status string
+		if err := rows.Scan(&index, &status); err != nil {
+			return 0, err
+		}
+		if StepStatus(status) == StepCompleted && index == completedThrough+1 {
+			completedThrough = index
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return completedThrough + 1, nil
+}
+
+func (c *Coordinator) setSagaStatus(ctx context.Context, sagaID string, status Status, cause error) {
+	var lastErr *string
+	if cause != nil {
+		s := cause.Error()
+		lastErr = &s
+	}
+	if _, err := c.db.Exec(ctx,
+		`UPDATE sagas SET status = $1, last_error = $2, updated_at = NOW() WHERE id = $3`,
+		status, lastErr, sagaID,
+	); err != nil {
+		slog.Error("saga: failed to update saga status", "saga_id", sagaID, "status", status, "error", err)
+	}
+}
+
+func (c *Coordinator) setStepStatus(ctx context.Context, sagaID string, index int, status StepStatus, cause error) {
+	var lastErr *string
+	if cause != nil {
+		s := cause.Error()
+		lastErr = &s
+	}
+	if _, err := c.db.Exec(ctx,
+		`UPDATE saga_steps SET status = $1, last_error = $2, updated_at = NOW() WHERE saga_id = $3 AND step_index = $4`,
+		status, lastErr, sagaID, index,
+	); err != nil {
+		slog.Error("saga: failed to update step status", "saga_id", sagaID, "step_index", index, "error", err)
+	}
+}
+
+func (c *Coordinator) incrementAttempts(ctx context.Context, sagaID string, index int) {
+	if _, err := c.db.Exec(ctx,
+		`UPDATE saga_steps SET attempts = attempts + 1, updated_at = NOW() WHERE saga_id = $1 AND step_index = $2`,
+		sagaID, index,
+	); err != nil {
+		slog.Error("saga: failed to increment step attempts", "saga_id", sagaID, "step_index", index, "error", err)
+	}
+}
+
+// stepSnapshot is one step's persisted state, used to render ListStuck.
+type stepSnapshot struct {
+	Name      string
+	Status    StepStatus
+	Attempts  int
+	LastError string
+}
+
+// StuckSaga describes a saga that didn't reach a clean terminal state -
+// either a compensation itself failed (StatusStuck), or it's still RUNNING
+// with no Builder registered to resume it.
+type StuckSaga struct {
+	ID        string
+	Type      string
+	Status    Status
+	LastError string
+	UpdatedAt time.Time
+	Steps     []stepSnapshot
+}
+
+// ListStuck returns every saga in StatusStuck, plus every saga still
+// StatusRunning with no registered Builder (ResumeAll's documented
+// leave-alone case), for an admin endpoint to surface.
+func (c *Coordinator) ListStuck(ctx context.Context) ([]StuckSaga, error) {
+	rows, err := c.db.Query(ctx,
+		`SELECT id, saga_type, status, COALESCE(last_error, ''), updated_at FROM sagas
+		 WHERE status = $1 OR status = $2 ORDER BY updated_at`,
+		StatusStuck, StatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("saga: list stuck sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var result []StuckSaga
+	for rows.Next() {
+		var s StuckSaga
+		var status string
+		if err := rows.Scan(&s.ID, &s.Type, &status, &s.LastError, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("saga: scan stuck saga: %w", err)
+		}
+		s.Status = Status(status)
+		if s.Status == StatusRunning {
+			if _, ok := c.builders[s.Type]; ok {
+				continue // RUNNING with a builder is an in-progress saga, not a stuck one
+			}
+		}
+
+		steps, err := c.stepsFor(ctx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.Steps = steps
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Coordinator) stepsFor(ctx context.Context, sagaID string) ([]stepSnapshot, error) {
+	rows, err := c.db.Query(ctx,
+		`SELECT name, status, attempts, COALESCE(last_error, '') FROM saga_steps WHERE saga_id = $1 ORDER BY step_index`,
+		sagaID)
+	if err != nil {
+		return nil, fmt.Errorf("saga: list steps: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []stepSnapshot
+	for rows.Next() {
+		var s stepSnapshot
+		var status string
+		if err := rows.Scan(&s.Name, &status, &s.Attempts, &s.LastError); err != nil {
+			return nil, fmt.Errorf("saga: scan step: %w", err)
+		}
+		s.Status = StepStatus(status)
+		steps = append(steps, s)
+	}
+	return steps, rows.Err()
+}
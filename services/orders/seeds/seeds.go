@@ -0,0 +1,91 @@
+// Package seeds loads a fixed set of demo orders into the database on
+// startup, so load tests and local runs have referenceable order IDs
+// without depending on a prior CreateOrder call.
+package seeds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Order is one entry of a seed file. ID is a caller-chosen, stable UUID so
+// it keeps referring to the same row across reseeds.
+type Order struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Status    string `json:"status"`
+}
+
+// Load reads the JSON order array at path and upserts each entry by ID.
+func Load(ctx context.Context, db *pgxpool.Pool, path string) (int, error) {
+	tracer := otel.Tracer("orders-service")
+	ctx, span := tracer.Start(ctx, "seeds.Load")
+	defer span.End()
+	span.SetAttributes(attribute.String("seed.path", path))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read seed file")
+		return 0, fmt.Errorf("failed to read seed file %q: %w", path, err)
+	}
+
+	var orders []Order
+	if err := json.Unmarshal(data, &orders); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to parse seed file")
+		return 0, fmt.Errorf("failed to parse seed file %q: %w", path, err)
+	}
+
+	span.SetAttributes(attribute.Int("seed.batch_size", len(orders)))
+
+	for _, o := range orders {
+		if o.ID == "" {
+			span.SetStatus(codes.Error, "seed order missing id")
+			return 0, fmt.Errorf("seed order for user %q is missing a stable id", o.UserID)
+		}
+
+		_, err := db.Exec(ctx,
+			`INSERT INTO orders (id, user_id, product_id, quantity, status)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (id) DO UPDATE
+			 SET user_id = EXCLUDED.user_id,
+			     product_id = EXCLUDED.product_id,
+			     quantity = EXCLUDED.quantity,
+			     status = EXCLUDED.status,
+			     updated_at = NOW()`,
+			o.ID, o.UserID, o.ProductID, o.Quantity, o.Status,
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to upsert seed order")
+			return 0, fmt.Errorf("failed to upsert seed order %q: %w", o.ID, err)
+		}
+
+		if _, err := db.Exec(ctx, `DELETE FROM order_items WHERE order_id = $1`, o.ID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to clear seed order items")
+			return 0, fmt.Errorf("failed to clear order_items for seed order %q: %w", o.ID, err)
+		}
+		if _, err := db.Exec(ctx,
+			`INSERT INTO order_items (order_id, product_id, quantity) VALUES ($1, $2, $3)`,
+			o.ID, o.ProductID, o.Quantity,
+		); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to insert seed order item")
+			return 0, fmt.Errorf("failed to insert order_items for seed order %q: %w", o.ID, err)
+		}
+	}
+
+	span.SetStatus(codes.Ok, "seed batch applied")
+	return len(orders), nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"net/http"
@@ -8,22 +9,30 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rafidoth/orders-service/config"
+	"github.com/rafidoth/orders-service/grpcserver"
 	"github.com/rafidoth/orders-service/handlers"
+	"github.com/rafidoth/orders-service/health"
 	"github.com/rafidoth/orders-service/middleware"
+	"github.com/rafidoth/orders-service/saga"
 	"github.com/riandyrn/otelchi"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
-	router  *chi.Mux
-	handler *handlers.OrdersHandler
-	cfg     *config.Config
+	router    *chi.Mux
+	handler   *handlers.OrdersHandler
+	health    *health.Registry
+	sagaAdmin *saga.AdminHandler
+	cfg       *config.Config
 }
 
-func NewServer(handler *handlers.OrdersHandler, cfg *config.Config) *Server {
+func NewServer(handler *handlers.OrdersHandler, healthRegistry *health.Registry, sagaAdmin *saga.AdminHandler, cfg *config.Config) *Server {
 	return &Server{
-		router:  chi.NewRouter(),
-		cfg:     cfg,
-		handler: handler,
+		router:    chi.NewRouter(),
+		cfg:       cfg,
+		handler:   handler,
+		health:    healthRegistry,
+		sagaAdmin: sagaAdmin,
 	}
 }
 
@@ -37,12 +46,27 @@ func (s *Server) registerRoutes() {
 	// Expose metrics endpoint for Prometheus scraping
 	s.router.Handle("/metrics", promhttp.Handler())
 
-	// Health check endpoint
-	s.router.Get("/health", s.handler.Health)
+	// Health check endpoints: /health/live for liveness, /health/ready for
+	// readiness (503s while a critical dependency is failing), /health for
+	// the full aggregate report.
+	s.router.Get("/health/live", s.health.Live)
+	s.router.Get("/health/ready", s.health.Ready)
+	s.router.Get("/health", s.health.Health)
 
-	// Order endpoints
-	s.router.Post("/orders", s.handler.CreateOrder)
-	s.router.Get("/orders/{id}", s.handler.GetOrder)
+	// Order endpoints require an authenticated caller.
+	s.router.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth)
+		r.Post("/orders", s.handler.CreateOrder)
+		r.Get("/orders/{id}", s.handler.GetOrder)
+		r.Get("/orders/{id}/events", s.handler.GetOrderEvents)
+		r.Get("/orders/{id}/payment", s.handler.GetOrderPaymentStatus)
+	})
+
+	// Admin surface for inspecting sagas that need manual recovery.
+	s.router.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth, middleware.RequireRole("admin"))
+		r.Get("/admin/sagas/stuck", s.sagaAdmin.ListStuck)
+	})
 }
 
 func (s *Server) Start() {
@@ -51,9 +75,27 @@ func (s *Server) Start() {
 	if s.cfg.Port == "" {
 		slog.Error("Port not specified in configuration")
 	}
+
+	if s.cfg.GRPCPort != "" {
+		go s.startGRPC()
+	}
+
 	slog.Info("Orders Service is starting.", "port", addr)
 	err := http.ListenAndServe(":"+addr, s.router)
 	if err != nil {
 		log.Fatal("ListenAndServe error: ", err)
 	}
 }
+
+// startGRPC runs the OrdersService gRPC server on its own port alongside the
+// chi HTTP server, sharing the same handler (and therefore the same
+// database pool and inventory client) as the HTTP transport.
+func (s *Server) startGRPC() {
+	grpcServer := grpc.NewServer(grpcserver.ServerOptions()...)
+	grpcserver.New(s.handler).Register(grpcServer)
+
+	slog.Info("Orders Service gRPC server is starting.", "port", s.cfg.GRPCPort)
+	if err := grpcserver.Serve(context.Background(), grpcServer, ":"+s.cfg.GRPCPort); err != nil {
+		slog.Error("gRPC ListenAndServe error", "error", err)
+	}
+}
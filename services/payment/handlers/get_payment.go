@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rafidoth/payment-service/middleware"
 	"github.com/rafidoth/payment-service/tracing"
 )
 
@@ -45,6 +47,11 @@ func (h *PaymentHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !isOwnerOrAdmin(ctx, payment.UserID) {
+		writeError(w, http.StatusForbidden, "You do not have access to this payment")
+		return
+	}
+
 	writeJSON(w, http.StatusOK, payment)
 }
 
@@ -87,5 +94,24 @@ func (h *PaymentHandler) GetPaymentByOrderID(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if !isOwnerOrAdmin(ctx, payment.UserID) {
+		writeError(w, http.StatusForbidden, "You do not have access to this payment")
+		return
+	}
+
 	writeJSON(w, http.StatusOK, payment)
 }
+
+// isOwnerOrAdmin reports whether the authenticated caller (set by
+// middleware.RequireAuth) is the payment's owner or has the admin role.
+func isOwnerOrAdmin(ctx context.Context, ownerUserID string) bool {
+	if userID, ok := middleware.UserID(ctx); ok && userID == ownerUserID {
+		return true
+	}
+	for _, role := range middleware.Roles(ctx) {
+		if role == "admin" {
+			return true
+		}
+	}
+	return false
+}
@@ -6,14 +6,16 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rafidoth/payment-service/outbox"
 )
 
 type PaymentHandler struct {
-	db *pgxpool.Pool
+	db          *pgxpool.Pool
+	outboxStore *outbox.Store
 }
 
-func New(conn *pgxpool.Pool) *PaymentHandler {
-	return &PaymentHandler{db: conn}
+func New(conn *pgxpool.Pool, outboxStore *outbox.Store) *PaymentHandler {
+	return &PaymentHandler{db: conn, outboxStore: outboxStore}
 }
 
 type Payment struct {
@@ -39,6 +41,9 @@ type ProcessPaymentRequest struct {
 	ExpiryYear     string  `json:"expiry_year"`
 	CVV            string  `json:"cvv"`
 	CardholderName string  `json:"cardholder_name"`
+	// IdempotencyKey is the body fallback for callers that can't set the
+	// Idempotency-Key header; the header takes precedence when both are set.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 type PaymentResponse struct {
@@ -47,15 +52,6 @@ type PaymentResponse struct {
 	Payment *Payment `json:"payment,omitempty"`
 }
 
-func (h *PaymentHandler) Health(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "healthy",
-		"service": "payment-service",
-	})
-}
-
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// idempotencyTTL bounds how long a stored response is replayed for a given
+// (user_id, idempotency_key) pair before the key is eligible for reuse.
+const idempotencyTTL = 24 * time.Hour
+
+// idempotencyCleanupInterval is how often StartIdempotencyCleanup sweeps
+// expired payment_idempotency rows.
+const idempotencyCleanupInterval = 1 * time.Hour
+
+// errIdempotencyKeyReplayMismatch means the caller reused an idempotency key
+// with a different request body than the one it was first stored with.
+var errIdempotencyKeyReplayMismatch = errors.New("idempotency key reused with a different request body")
+
+// idempotencyPendingStatus is the placeholder response_status claimIdempotencyKey
+// writes before the payment has actually been decided. It's never a real
+// HTTP status code. lookupIdempotency doesn't check for it: another
+// transaction only sees a claimed row once storeIdempotency has overwritten
+// it and the claiming transaction has committed, and the row is gone if that
+// transaction rolls back instead.
+const idempotencyPendingStatus = 0
+
+// idempotencyRecord is a previously stored response for an idempotency key,
+// or nil if the key hasn't been seen (or its TTL has expired).
+type idempotencyRecord struct {
+	Status int
+	Body   json.RawMessage
+}
+
+// hashRequestBody fingerprints the raw request body so a replayed idempotency
+// key can be matched against the exact request it was first used with.
+func hashRequestBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// claimIdempotencyKey inserts a placeholder row for (userID, key) within tx.
+// SELECT ... FOR UPDATE can't lock a row that doesn't exist yet, so without
+// this, two concurrent first-use requests for the same key both see no
+// record and both process the payment; inserting here instead means the
+// second request's INSERT blocks on the unique (user_id, idempotency_key)
+// index until this transaction commits or rolls back, so it always sees
+// either the first request's finished result or - if that transaction
+// rolled back - a clean slot to claim itself. Returns true if this call
+// claimed the key: the caller should process the payment and finalize it
+// with storeIdempotency. false means a row already exists; the caller
+// should fall back to lookupIdempotency to see whether it's a live record
+// to replay or an expired one free to reuse.
+func claimIdempotencyKey(ctx context.Context, tx pgx.Tx, userID, key, requestHash string) (bool, error) {
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO payment_idempotency (user_id, idempotency_key, request_hash, response_status, response_body, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (user_id, idempotency_key) DO NOTHING`,
+		userID, key, requestHash, idempotencyPendingStatus, []byte("{}"), time.Now().Add(idempotencyTTL),
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// lookupIdempotency checks for a live record under (userID, key) within tx,
+// locking the row (if any) so concurrent replays of the same key serialize
+// instead of racing to process the payment twice.
+func lookupIdempotency(ctx context.Context, tx pgx.Tx, userID, key string) (*idempotencyRecord, string, error) {
+	var requestHash string
+	var rec idempotencyRecord
+	var expiresAt time.Time
+
+	err := tx.QueryRow(ctx,
+		`SELECT request_hash, response_status, response_body, expires_at
+		 FROM payment_idempotency
+		 WHERE user_id = $1 AND idempotency_key = $2
+		 FOR UPDATE`,
+		userID, key,
+	).Scan(&requestHash, &rec.Status, &rec.Body, &expiresAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if time.Now().After(expiresAt) {
+		// Expired: treat as unseen, but keep the hash so the caller can still
+		// detect a body mismatch against the stale record if it wants to.
+		return nil, requestHash, nil
+	}
+	return &rec, requestHash, nil
+}
+
+// storeIdempotency persists the response that was just produced for
+// (userID, key) so a retry within the TTL replays it verbatim.
+func storeIdempotency(ctx context.Context, tx pgx.Tx, userID, key, requestHash string, status int, body []byte) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO payment_idempotency (user_id, idempotency_key, request_hash, response_status, response_body, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (user_id, idempotency_key) DO UPDATE
+		 SET request_hash = EXCLUDED.request_hash,
+		     response_status = EXCLUDED.response_status,
+		     response_body = EXCLUDED.response_body,
+		     created_at = NOW(),
+		     expires_at = EXCLUDED.expires_at`,
+		userID, key, requestHash, status, body, time.Now().Add(idempotencyTTL),
+	)
+	return err
+}
+
+// StartIdempotencyCleanup periodically deletes expired payment_idempotency
+// rows until ctx is cancelled. It runs as a background goroutine from
+// main.go, the same way outbox.Publisher does - expired rows are otherwise
+// harmless (lookupIdempotency already treats them as unseen) but would
+// accumulate forever without it.
+func StartIdempotencyCleanup(ctx context.Context, db *pgxpool.Pool) {
+	ticker := time.NewTicker(idempotencyCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tag, err := db.Exec(ctx, `DELETE FROM payment_idempotency WHERE expires_at < NOW()`)
+			if err != nil {
+				slog.Error("failed to clean up expired payment idempotency keys", "error", err)
+				continue
+			}
+			if tag.RowsAffected() > 0 {
+				slog.Info("cleaned up expired payment idempotency keys", "count", tag.RowsAffected())
+			}
+		}
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/rafidoth/payment-service/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -26,8 +29,15 @@ func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request)
 	ctx, span := tracing.StartSpan(r.Context(), "ProcessPayment")
 	defer span.End()
 
+	rawBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("failed to read request body", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
 	var req ProcessPaymentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(rawBody, &req); err != nil {
 		slog.Error("failed to decode request", "error", err, "traceID", tracing.GetTraceID(ctx))
 		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
@@ -40,18 +50,142 @@ func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Extract card info
+	if !isOwnerOrAdmin(ctx, req.UserID) {
+		writeError(w, http.StatusForbidden, "Cannot process a payment for another user")
+		return
+	}
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		idempotencyKey = req.IdempotencyKey
+	}
+
+	if idempotencyKey != "" {
+		h.processPaymentIdempotent(ctx, w, &req, idempotencyKey, rawBody)
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		slog.Error("failed to begin payment transaction", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	status, failureReason, payment, err := h.processPayment(ctx, &req, tx)
+	if err != nil {
+		slog.Error("failed to save payment", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("failed to commit payment transaction", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+
+	writeJSON(w, statusCodeFor(status), PaymentResponse{
+		Success: status == StatusCompleted,
+		Message: getPaymentMessage(status, failureReason),
+		Payment: payment,
+	})
+}
+
+// processPaymentIdempotent wraps the payment decision and persistence in a
+// single transaction alongside the payment_idempotency record, so a stored
+// response and its payment row either both commit or neither does. A replay
+// with the same key and body returns the stored response verbatim; a replay
+// with the same key and a different body is rejected with 422. Two
+// concurrent first-use requests for the same key are serialized by
+// claimIdempotencyKey rather than racing each other into processPayment.
+func (h *PaymentHandler) processPaymentIdempotent(ctx context.Context, w http.ResponseWriter, req *ProcessPaymentRequest, idempotencyKey string, rawBody []byte) {
+	requestHash := hashRequestBody(rawBody)
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		slog.Error("failed to begin idempotent payment transaction", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	claimed, err := claimIdempotencyKey(ctx, tx, req.UserID, idempotencyKey, requestHash)
+	if err != nil {
+		slog.Error("failed to claim idempotency key", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+
+	if !claimed {
+		existing, storedHash, err := lookupIdempotency(ctx, tx, req.UserID, idempotencyKey)
+		if err != nil {
+			slog.Error("failed to look up idempotency key", "error", err, "traceID", tracing.GetTraceID(ctx))
+			writeError(w, http.StatusInternalServerError, "Failed to process payment")
+			return
+		}
+		if existing != nil {
+			if storedHash != requestHash {
+				writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(existing.Status)
+			w.Write(existing.Body)
+			return
+		}
+		// existing == nil: the stored record had expired, so the key is free
+		// to reuse - fall through and process the payment, then overwrite it.
+	}
+
+	status, failureReason, payment, err := h.processPayment(ctx, req, tx)
+	if err != nil {
+		slog.Error("failed to save payment", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+
+	responseStatus := statusCodeFor(status)
+	responseBody, err := json.Marshal(PaymentResponse{
+		Success: status == StatusCompleted,
+		Message: getPaymentMessage(status, failureReason),
+		Payment: payment,
+	})
+	if err != nil {
+		slog.Error("failed to marshal payment response", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+
+	if err := storeIdempotency(ctx, tx, req.UserID, idempotencyKey, requestHash, responseStatus, responseBody); err != nil {
+		slog.Error("failed to store idempotency record", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		slog.Error("failed to commit idempotent payment transaction", "error", err, "traceID", tracing.GetTraceID(ctx))
+		writeError(w, http.StatusInternalServerError, "Failed to process payment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(responseStatus)
+	w.Write(responseBody)
+}
+
+// processPayment runs the card-brand/decline decision and persists the
+// resulting payment row within tx, enqueuing the matching payment.completed
+// or payment.failed outbox event in the same transaction so a reader can
+// never observe one without the other.
+func (h *PaymentHandler) processPayment(ctx context.Context, req *ProcessPaymentRequest, tx pgx.Tx) (status, failureReason string, payment *Payment, err error) {
 	cardNumber := strings.ReplaceAll(req.CardNumber, " ", "")
 	cardLastFour := cardNumber[len(cardNumber)-4:]
 	cardBrand := detectCardBrand(cardNumber)
-
-	// Generate transaction ID
+	cardToken := Tokenize(cardNumber)
 	transactionID := generateTransactionID()
 
-	// Check for test decline card
-	var status string
-	var failureReason string
-
 	if cardNumber == DeclineTestCard {
 		status = StatusFailed
 		failureReason = "Card declined - insufficient funds"
@@ -60,26 +194,50 @@ func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request)
 		status = StatusCompleted
 		slog.Info("payment approved", "orderID", req.OrderID, "traceID", tracing.GetTraceID(ctx))
 	}
+	// cardNumber and req.CVV must not be referenced again below this point -
+	// only cardLastFour, cardBrand, and cardToken are ever persisted or logged.
 
-	// Save payment to database
-	payment, err := h.savePayment(ctx, &req, status, cardLastFour, cardBrand, transactionID, failureReason)
+	payment, err = h.savePayment(ctx, tx, req, status, cardLastFour, cardBrand, cardToken, transactionID, failureReason)
 	if err != nil {
-		slog.Error("failed to save payment", "error", err, "traceID", tracing.GetTraceID(ctx))
-		writeError(w, http.StatusInternalServerError, "Failed to process payment")
-		return
+		return "", "", nil, err
 	}
 
-	response := PaymentResponse{
-		Success: status == StatusCompleted,
-		Message: getPaymentMessage(status, failureReason),
-		Payment: payment,
+	if h.outboxStore != nil {
+		eventType := "payment.completed"
+		if status == StatusFailed {
+			eventType = "payment.failed"
+		}
+		if err := h.outboxStore.Enqueue(ctx, tx, payment.OrderID, eventType, paymentEvent{
+			PaymentID:     payment.ID,
+			OrderID:       payment.OrderID,
+			UserID:        payment.UserID,
+			Status:        status,
+			FailureReason: failureReason,
+		}, trace.SpanFromContext(ctx)); err != nil {
+			return "", "", nil, err
+		}
 	}
 
+	return status, failureReason, payment, nil
+}
+
+// paymentEvent is the payload published for the payment.completed and
+// payment.failed outbox events.
+type paymentEvent struct {
+	PaymentID     string `json:"payment_id"`
+	OrderID       string `json:"order_id"`
+	UserID        string `json:"user_id"`
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// statusCodeFor maps a payment's terminal status to the HTTP status the
+// ProcessPayment response is returned with.
+func statusCodeFor(status string) int {
 	if status == StatusCompleted {
-		writeJSON(w, http.StatusOK, response)
-	} else {
-		writeJSON(w, http.StatusPaymentRequired, response)
+		return http.StatusOK
 	}
+	return http.StatusPaymentRequired
 }
 
 func validatePaymentRequest(req *ProcessPaymentRequest) error {
@@ -97,6 +255,9 @@ func validatePaymentRequest(req *ProcessPaymentRequest) error {
 	if len(cardNumber) < 13 || len(cardNumber) > 19 {
 		return fmt.Errorf("invalid card number")
 	}
+	if !isValidLuhn(cardNumber) {
+		return fmt.Errorf("invalid card number")
+	}
 	if req.ExpiryMonth == "" || req.ExpiryYear == "" {
 		return fmt.Errorf("expiry date is required")
 	}
@@ -110,29 +271,6 @@ func validatePaymentRequest(req *ProcessPaymentRequest) error {
 	return nil
 }
 
-func detectCardBrand(cardNumber string) string {
-	if len(cardNumber) == 0 {
-		return "Unknown"
-	}
-
-	firstDigit := cardNumber[0]
-	switch firstDigit {
-	case '4':
-		return "Visa"
-	case '5':
-		return "Mastercard"
-	case '3':
-		if len(cardNumber) > 1 && (cardNumber[1] == '4' || cardNumber[1] == '7') {
-			return "American Express"
-		}
-		return "Unknown"
-	case '6':
-		return "Discover"
-	default:
-		return "Unknown"
-	}
-}
-
 func generateTransactionID() string {
 	return fmt.Sprintf("txn_%s", uuid.New().String()[:8])
 }
@@ -147,10 +285,17 @@ func getPaymentMessage(status, failureReason string) string {
 	return "Payment failed"
 }
 
-func (h *PaymentHandler) savePayment(ctx context.Context, req *ProcessPaymentRequest, status, cardLastFour, cardBrand, transactionID, failureReason string) (*Payment, error) {
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx, so savePayment can
+// run standalone or as part of the idempotency transaction without knowing
+// which one it was handed.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (h *PaymentHandler) savePayment(ctx context.Context, q querier, req *ProcessPaymentRequest, status, cardLastFour, cardBrand, cardToken, transactionID, failureReason string) (*Payment, error) {
 	query := `
-		INSERT INTO payments (order_id, user_id, amount, status, card_last_four, card_brand, transaction_id, failure_reason)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO payments (order_id, user_id, amount, status, card_last_four, card_brand, card_token, transaction_id, failure_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, order_id, user_id, amount, status, card_last_four, card_brand, transaction_id, failure_reason, created_at, updated_at
 	`
 
@@ -160,13 +305,14 @@ func (h *PaymentHandler) savePayment(ctx context.Context, req *ProcessPaymentReq
 		failureReasonPtr = &failureReason
 	}
 
-	err := h.db.QueryRow(ctx, query,
+	err := q.QueryRow(ctx, query,
 		req.OrderID,
 		req.UserID,
 		req.Amount,
 		status,
 		cardLastFour,
 		cardBrand,
+		cardToken,
 		transactionID,
 		failureReasonPtr,
 	).Scan(
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rafidoth/payment-service/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const StatusRefunded = "REFUNDED"
+
+// refundEvent is the payload published for the payment.refunded outbox
+// event, mirroring paymentEvent's shape for consistency with the other
+// payment lifecycle events.
+type refundEvent struct {
+	PaymentID string `json:"payment_id"`
+	OrderID   string `json:"order_id"`
+	UserID    string `json:"user_id"`
+}
+
+// RefundPayment marks a completed payment as refunded. It's idempotent:
+// refunding an already-refunded payment just returns it unchanged, since a
+// saga compensation may retry after a transient failure.
+func (h *PaymentHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.StartSpan(r.Context(), "RefundPayment")
+	defer span.End()
+
+	paymentID := chi.URLParam(r, "id")
+	if paymentID == "" {
+		writeError(w, http.StatusBadRequest, "Payment ID is required")
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to process refund")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var payment Payment
+	err = tx.QueryRow(ctx,
+		`SELECT id, order_id, user_id, amount, status, card_last_four, card_brand, transaction_id, COALESCE(failure_reason, ''), created_at, updated_at
+		 FROM payments WHERE id = $1 FOR UPDATE`,
+		paymentID,
+	).Scan(&payment.ID, &payment.OrderID, &payment.UserID, &payment.Amount, &payment.Status,
+		&payment.CardLastFour, &payment.CardBrand, &payment.TransactionID, &payment.FailureReason,
+		&payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Payment not found")
+		return
+	}
+
+	if !isOwnerOrAdmin(ctx, payment.UserID) {
+		writeError(w, http.StatusForbidden, "You do not have access to this payment")
+		return
+	}
+
+	if payment.Status == StatusRefunded {
+		writeJSON(w, http.StatusOK, PaymentResponse{Success: true, Message: "Payment already refunded", Payment: &payment})
+		return
+	}
+
+	if payment.Status != StatusCompleted {
+		writeError(w, http.StatusConflict, "Only a completed payment can be refunded")
+		return
+	}
+
+	if err := tx.QueryRow(ctx,
+		`UPDATE payments SET status = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at`,
+		StatusRefunded, paymentID,
+	).Scan(&payment.UpdatedAt); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to process refund")
+		return
+	}
+	payment.Status = StatusRefunded
+
+	if h.outboxStore != nil {
+		if err := h.outboxStore.Enqueue(ctx, tx, payment.OrderID, "payment.refunded", refundEvent{
+			PaymentID: payment.ID,
+			OrderID:   payment.OrderID,
+			UserID:    payment.UserID,
+		}, trace.SpanFromContext(ctx)); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to process refund")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to process refund")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PaymentResponse{Success: true, Message: "Payment refunded", Payment: &payment})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+)
+
+// secret is resolved once at process startup by tokenizeSecret.
+var secret = tokenizeSecret()
+
+// tokenizeSecret is the HMAC pepper used to derive card tokens. Keyed
+// hashing (rather than a plain hash) means the token can't be reversed by
+// brute-forcing the card number space, which a 16-digit PAN's limited
+// entropy would otherwise make feasible. There's no safe fallback for a
+// missing secret - a literal baked into source would defeat tokenization in
+// any deployment that forgets to set it - so we fail closed at startup
+// instead.
+func tokenizeSecret() []byte {
+	s := os.Getenv("TOKENIZATION_SECRET")
+	if s == "" {
+		log.Fatal("TOKENIZATION_SECRET environment variable is required")
+	}
+	return []byte(s)
+}
+
+// Tokenize derives an opaque, non-reversible token for pan. Only this token,
+// plus card_last_four and card_brand, is ever persisted - the raw PAN is
+// discarded once processPayment returns.
+func Tokenize(pan string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(pan))
+	return "tok_" + hex.EncodeToString(mac.Sum(nil))[:24]
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import "strconv"
+
+// isValidLuhn reports whether cardNumber (digits only, spaces already
+// stripped) passes the Luhn mod-10 checksum used by every major card
+// network to catch typos and transposition errors in a PAN.
+func isValidLuhn(cardNumber string) bool {
+	if cardNumber == "" {
+		return false
+	}
+
+	sum := 0
+	alternate := false
+	for i := len(cardNumber) - 1; i >= 0; i-- {
+		d := int(cardNumber[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if alternate {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		alternate = !alternate
+	}
+	return sum%10 == 0
+}
+
+// firstNDigits parses the first n characters of s as an integer, returning
+// -1 if s is shorter than n or they aren't digits.
+func firstNDigits(s string, n int) int {
+	if len(s) < n {
+		return -1
+	}
+	v, err := strconv.Atoi(s[:n])
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+// detectCardBrand classifies cardNumber by IIN (the issuer-identifying
+// prefix), covering the ranges the major networks actually issue from
+// rather than just the first digit.
+func detectCardBrand(cardNumber string) string {
+	if len(cardNumber) == 0 {
+		return "Unknown"
+	}
+
+	prefix2 := firstNDigits(cardNumber, 2)
+	prefix3 := firstNDigits(cardNumber, 3)
+	prefix4 := firstNDigits(cardNumber, 4)
+
+	switch {
+	case cardNumber[0] == '4':
+		return "Visa"
+	case (prefix2 >= 51 && prefix2 <= 55) || (prefix4 >= 2221 && prefix4 <= 2720):
+		return "Mastercard"
+	case prefix2 == 34 || prefix2 == 37:
+		return "American Express"
+	case prefix4 == 6011 || prefix2 == 65 || (prefix3 >= 644 && prefix3 <= 649):
+		return "Discover"
+	case (prefix3 >= 300 && prefix3 <= 305) || prefix2 == 36 || prefix2 == 38:
+		return "Diners Club"
+	case prefix4 >= 3528 && prefix4 <= 3589:
+		return "JCB"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// DBPinger is satisfied by *pgxpool.Pool. It's declared locally so this
+// package doesn't need to import pgxpool just to describe a Ping method.
+type DBPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// DBChecker checks connectivity to a service's primary Postgres pool.
+func DBChecker(db DBPinger) Checker {
+	return NewCheck("database", db.Ping)
+}
+
+// AMQPConn is satisfied by *amqp.Connection.
+type AMQPConn interface {
+	IsClosed() bool
+}
+
+var errAMQPClosed = errors.New("connection is closed")
+
+// AMQPChecker reports the RabbitMQ connection the outbox publisher ships
+// events over. conn is nil when publishing was never configured, in which
+// case the checker always fails - callers should register it as
+// non-critical in that case.
+func AMQPChecker(conn AMQPConn) Checker {
+	return NewCheck("rabbitmq", func(ctx context.Context) error {
+		// A nil *amqp.Connection arrives here as a non-nil interface holding
+		// a nil pointer, so that case is caught before calling IsClosed.
+		if v := reflect.ValueOf(conn); conn == nil || (v.Kind() == reflect.Ptr && v.IsNil()) || conn.IsClosed() {
+			return errAMQPClosed
+		}
+		return nil
+	})
+}
+
+// HTTPChecker reports whether a downstream HTTP dependency's liveness
+// endpoint responds. name identifies the dependency in the response (e.g.
+// "inventory-service") independently of its URL.
+func HTTPChecker(name, baseURL string) Checker {
+	client := &http.Client{}
+	return NewCheck(name, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/health/live", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,73 @@
+// Package logging provides a defense-in-depth slog wrapper for services
+// that handle card data: if a bug logs a raw PAN in the message or in a
+// string attribute (including one inside a group), it reaches the underlying
+// handler (stdout, a collector, ...) redacted. Values of other kinds, such as
+// errors or structs passed via slog.Any, are passed through unchanged.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+)
+
+var digitRun = regexp.MustCompile(`\d{13,19}`)
+
+// RedactingHandler wraps another slog.Handler and scrubs any 13-19 digit
+// run - long enough to be a PAN - out of string attribute values before
+// they reach it.
+type RedactingHandler struct {
+	next slog.Handler
+}
+
+// NewRedactingHandler wraps next with PAN redaction.
+func NewRedactingHandler(next slog.Handler) *RedactingHandler {
+	return &RedactingHandler{next: next}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	message := digitRun.ReplaceAllString(record.Message, "[redacted]")
+	redacted := slog.NewRecord(record.Time, record.Level, message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = redactValue(a.Value)
+	return a
+}
+
+func redactValue(v slog.Value) slog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.StringValue(digitRun.ReplaceAllString(v.String(), "[redacted]"))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, a := range group {
+			redacted[i] = redactAttr(a)
+		}
+		return slog.GroupValue(redacted...)
+	default:
+		return v
+	}
+}
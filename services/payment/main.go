@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 
+	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rafidoth/payment-service/config"
 	"github.com/rafidoth/payment-service/db"
 	"github.com/rafidoth/payment-service/handlers"
+	"github.com/rafidoth/payment-service/health"
+	"github.com/rafidoth/payment-service/logging"
+	"github.com/rafidoth/payment-service/outbox"
 	"github.com/rafidoth/payment-service/tracing"
 )
 
+// paymentsExchange is the topic exchange the outbox publisher ships
+// payment.completed, payment.failed and payment.refunded events to.
+const paymentsExchange = "payments.events"
+
 func init() {
 	opts := &slog.HandlerOptions{
 		AddSource: true,
@@ -28,7 +37,11 @@ func init() {
 		},
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, opts)
+	// Card numbers and CVVs must never reach stdout: Tokenize already keeps
+	// them out of anything we deliberately log, and this redacting wrapper
+	// is the defense-in-depth backstop for any 13-19 digit run we don't (a
+	// CVV is too short for it to catch).
+	handler := logging.NewRedactingHandler(slog.NewTextHandler(os.Stdout, opts))
 	slog.SetDefault(slog.New(handler))
 }
 
@@ -54,7 +67,36 @@ func main() {
 		os.Exit(1)
 	}
 
-	handler := handlers.New(conn)
-	server := NewServer(handler, cfg)
+	outboxStore := outbox.NewStore()
+	var amqpConn *amqp.Connection
+	if cfg.RabbitMqURL != "" {
+		amqpConn, err = amqp.Dial(cfg.RabbitMqURL)
+		if err != nil {
+			slog.Error("unable to connect to RabbitMQ, outbox publishing disabled", "error", err)
+		} else {
+			amqpChannel, err := amqpConn.Channel()
+			if err != nil {
+				slog.Error("unable to open RabbitMQ channel, outbox publishing disabled", "error", err)
+			} else if err := amqpChannel.ExchangeDeclare(paymentsExchange, "topic", true, false, false, false, nil); err != nil {
+				slog.Error("unable to declare payments exchange, outbox publishing disabled", "error", err)
+			} else {
+				publisher := outbox.NewPublisher(conn, amqpChannel, paymentsExchange)
+				publisherCtx, cancelPublisher := context.WithCancel(context.Background())
+				defer cancelPublisher()
+				go publisher.Start(publisherCtx)
+			}
+		}
+	}
+
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register(health.DBChecker(conn), true, 0)
+	healthRegistry.Register(health.AMQPChecker(amqpConn), false, 0)
+
+	idempotencyCleanupCtx, cancelIdempotencyCleanup := context.WithCancel(context.Background())
+	defer cancelIdempotencyCleanup()
+	go handlers.StartIdempotencyCleanup(idempotencyCleanupCtx, conn)
+
+	handler := handlers.New(conn, outboxStore)
+	server := NewServer(handler, healthRegistry, cfg)
 	server.Start()
 }
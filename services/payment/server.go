@@ -9,6 +9,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rafidoth/payment-service/config"
 	"github.com/rafidoth/payment-service/handlers"
+	"github.com/rafidoth/payment-service/health"
 	"github.com/rafidoth/payment-service/middleware"
 	"github.com/riandyrn/otelchi"
 )
@@ -16,14 +17,16 @@ import (
 type Server struct {
 	router  *chi.Mux
 	handler *handlers.PaymentHandler
+	health  *health.Registry
 	cfg     *config.Config
 }
 
-func NewServer(handler *handlers.PaymentHandler, cfg *config.Config) *Server {
+func NewServer(handler *handlers.PaymentHandler, healthRegistry *health.Registry, cfg *config.Config) *Server {
 	return &Server{
 		router:  chi.NewRouter(),
 		cfg:     cfg,
 		handler: handler,
+		health:  healthRegistry,
 	}
 }
 
@@ -37,13 +40,21 @@ func (s *Server) registerRoutes() {
 	// Expose metrics endpoint for Prometheus scraping
 	s.router.Handle("/metrics", promhttp.Handler())
 
-	// Health check endpoint
-	s.router.Get("/health", s.handler.Health)
+	// Health check endpoints: /health/live for liveness, /health/ready for
+	// readiness (503s while a critical dependency is failing), /health for
+	// the full aggregate report.
+	s.router.Get("/health/live", s.health.Live)
+	s.router.Get("/health/ready", s.health.Ready)
+	s.router.Get("/health", s.health.Health)
 
-	// Payment endpoints
-	s.router.Post("/payments", s.handler.ProcessPayment)
-	s.router.Get("/payments/{id}", s.handler.GetPayment)
-	s.router.Get("/payments/order/{orderId}", s.handler.GetPaymentByOrderID)
+	// Payment endpoints require an authenticated caller.
+	s.router.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth)
+		r.Post("/payments", s.handler.ProcessPayment)
+		r.Get("/payments/{id}", s.handler.GetPayment)
+		r.Post("/payments/{id}/refund", s.handler.RefundPayment)
+		r.Get("/payments/order/{orderId}", s.handler.GetPaymentByOrderID)
+	})
 }
 
 func (s *Server) Start() {